@@ -47,5 +47,14 @@ func buildJobCmd() *cobra.Command {
 	job.InitResumeFlags(jobResumeCmd)
 	jobCmd.AddCommand(jobResumeCmd)
 
+	jobViewCmd := &cobra.Command{
+		Use: "view",
+		Run: func(cmd *cobra.Command, args []string) {
+			checkError(cmd, job.ViewJob())
+		},
+	}
+	job.InitViewFlags(jobViewCmd)
+	jobCmd.AddCommand(jobViewCmd)
+
 	return jobCmd
 }
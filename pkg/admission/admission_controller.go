@@ -18,6 +18,8 @@ package admission
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/golang/glog"
 
@@ -65,46 +67,196 @@ func ToAdmissionResponse(err error) *v1beta1.AdmissionResponse {
 func ValidatePolicies(policies []v1alpha1.LifecyclePolicy) error {
 	var err error
 	policyEvents := map[v1alpha1.Event]struct{}{}
-	exitCodes := map[int32]struct{}{}
+	var exitCodes []*v1alpha1.ExitCodeRequirement
 
 	for _, policy := range policies {
-		if policy.Event != "" && policy.ExitCode != nil {
+		hasEvents := policy.Event != "" || len(policy.Events) != 0
+		hasExitCodes := policy.ExitCode != nil || policy.ExitCodes != nil
+
+		if hasEvents && hasExitCodes {
 			err = multierror.Append(err, fmt.Errorf("must not specify event and exitCode simultaneously"))
 			break
 		}
 
-		if policy.Event == "" && policy.ExitCode == nil {
+		if !hasEvents && !hasExitCodes {
 			err = multierror.Append(err, fmt.Errorf("either event and exitCode should be specified"))
 			break
 		}
 
-		if policy.Event != "" {
-			// TODO: check event is in supported Event
-			if _, found := policyEvents[policy.Event]; found {
-				err = multierror.Append(err, fmt.Errorf("duplicate event %v", policy.Event))
-				break
-			} else {
-				policyEvents[policy.Event] = struct{}{}
+		if policy.Timeout != nil && policy.Timeout.Duration < 0 {
+			err = multierror.Append(err, fmt.Errorf("timeout %v must not be negative", policy.Timeout.Duration))
+			break
+		}
+
+		if hasEvents {
+			events := policy.Events
+			if policy.Event != "" {
+				events = append([]v1alpha1.Event{policy.Event}, events...)
+			}
+			for _, event := range events {
+				// TODO: check event is in supported Event
+				if event == "" {
+					err = multierror.Append(err, fmt.Errorf("event must not be empty"))
+					break
+				}
+				if policy.Action == v1alpha1.RestartTaskAction &&
+					(event == v1alpha1.JobUnknownEvent || event == v1alpha1.JobUnschedulableEvent) {
+					err = multierror.Append(err, fmt.Errorf("action %s can not work together with job level event %s",
+						policy.Action, event))
+					break
+				}
+				if _, found := policyEvents[event]; found {
+					err = multierror.Append(err, fmt.Errorf("duplicate event %v", event))
+					break
+				} else {
+					policyEvents[event] = struct{}{}
+				}
 			}
-		} else {
+			continue
+		}
+
+		if policy.ExitCode != nil && policy.ExitCodes != nil {
+			err = multierror.Append(err, fmt.Errorf("must not specify exitCode and exitCodes simultaneously"))
+			break
+		}
+
+		requirement := policy.ExitCodes
+		if policy.ExitCode != nil {
 			if *policy.ExitCode == 0 {
 				err = multierror.Append(err, fmt.Errorf("0 is not a valid error code"))
 				break
 			}
-			if _, found := exitCodes[*policy.ExitCode]; found {
-				err = multierror.Append(err, fmt.Errorf("duplicate exitCode %v", *policy.ExitCode))
+			requirement = &v1alpha1.ExitCodeRequirement{
+				Operator: v1alpha1.ExitCodeOpIn,
+				Values:   []int32{*policy.ExitCode},
+			}
+		} else if e := validateExitCodes(requirement); e != nil {
+			err = multierror.Append(err, e)
+			break
+		}
+
+		for _, other := range exitCodes {
+			if exitCodesOverlapped(requirement, other) {
+				err = multierror.Append(err, fmt.Errorf("duplicate exitCode %v", exitCodesString(requirement)))
 				break
-			} else {
-				exitCodes[*policy.ExitCode] = struct{}{}
 			}
 		}
+		exitCodes = append(exitCodes, requirement)
 	}
 
-	if _, found := policyEvents[v1alpha1.AnyEvent]; found && len(policyEvents) > 1 {
-		err = multierror.Append(err, fmt.Errorf("if there's * here, no other policy should be here"))
+	return err
+}
+
+func validateExitCodes(requirement *v1alpha1.ExitCodeRequirement) error {
+	switch requirement.Operator {
+	case v1alpha1.ExitCodeOpIn, v1alpha1.ExitCodeOpNotIn:
+	default:
+		return fmt.Errorf("unknown operator %s of exitCodes", requirement.Operator)
 	}
 
-	return err
+	if len(requirement.Values) == 0 && len(requirement.Ranges) == 0 {
+		return fmt.Errorf("either values or ranges of exitCodes should be specified")
+	}
+
+	for _, value := range requirement.Values {
+		if value == 0 {
+			return fmt.Errorf("0 is not a valid error code")
+		}
+	}
+
+	for _, r := range requirement.Ranges {
+		if r.Min > r.Max {
+			return fmt.Errorf("min %d must not be greater than max %d of exitCodes range", r.Min, r.Max)
+		}
+		if r.Min <= 0 && r.Max >= 0 {
+			return fmt.Errorf("0 is not a valid error code")
+		}
+	}
+
+	return nil
+}
+
+// exitCodesOverlapped checks whether any non-zero exit code is matched by both of the requirements.
+func exitCodesOverlapped(a, b *v1alpha1.ExitCodeRequirement) bool {
+	switch {
+	case a.Operator == v1alpha1.ExitCodeOpIn && b.Operator == v1alpha1.ExitCodeOpIn:
+		for _, ra := range exitCodeRanges(a) {
+			for _, rb := range exitCodeRanges(b) {
+				if ra.Min <= rb.Max && rb.Min <= ra.Max {
+					return true
+				}
+			}
+		}
+		return false
+	case a.Operator == v1alpha1.ExitCodeOpIn:
+		return !exitCodeRangesCovered(exitCodeRanges(a), exitCodeRanges(b))
+	case b.Operator == v1alpha1.ExitCodeOpIn:
+		return !exitCodeRangesCovered(exitCodeRanges(b), exitCodeRanges(a))
+	default:
+		// Both of them match almost all the exit codes.
+		return true
+	}
+}
+
+// exitCodeRanges returns the set of exit codes as sorted and merged ranges.
+func exitCodeRanges(requirement *v1alpha1.ExitCodeRequirement) []v1alpha1.ExitCodeRange {
+	ranges := append([]v1alpha1.ExitCodeRange{}, requirement.Ranges...)
+	for _, value := range requirement.Values {
+		ranges = append(ranges, v1alpha1.ExitCodeRange{Min: value, Max: value})
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].Min < ranges[j].Min
+	})
+
+	var merged []v1alpha1.ExitCodeRange
+	for _, r := range ranges {
+		last := len(merged) - 1
+		if last >= 0 && int64(r.Min) <= int64(merged[last].Max)+1 {
+			if r.Max > merged[last].Max {
+				merged[last].Max = r.Max
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	return merged
+}
+
+// exitCodeRangesCovered checks whether all the exit codes of ranges are in the merged ranges of covers.
+func exitCodeRangesCovered(ranges, covers []v1alpha1.ExitCodeRange) bool {
+	for _, r := range ranges {
+		covered := false
+		for _, c := range covers {
+			if c.Min <= r.Min && r.Max <= c.Max {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+
+	return true
+}
+
+func exitCodesString(requirement *v1alpha1.ExitCodeRequirement) string {
+	if requirement.Operator == v1alpha1.ExitCodeOpIn &&
+		len(requirement.Values) == 1 && len(requirement.Ranges) == 0 {
+		return fmt.Sprintf("%d", requirement.Values[0])
+	}
+
+	var codes []string
+	for _, value := range requirement.Values {
+		codes = append(codes, fmt.Sprintf("%d", value))
+	}
+	for _, r := range requirement.Ranges {
+		codes = append(codes, fmt.Sprintf("%d-%d", r.Min, r.Max))
+	}
+
+	return fmt.Sprintf("%s [%s]", requirement.Operator, strings.Join(codes, ","))
 }
 
 func DecodeJob(object runtime.RawExtension, resource metav1.GroupVersionResource) (v1alpha1.Job, error) {
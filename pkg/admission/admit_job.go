@@ -24,11 +24,13 @@ import (
 	"github.com/golang/glog"
 
 	"k8s.io/api/admission/v1beta1"
+	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation"
 
 	v1alpha1 "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
 	"volcano.sh/volcano/pkg/controllers/job/plugins"
+	vkinterface "volcano.sh/volcano/pkg/controllers/job/plugins/interface"
 )
 
 // job admit.
@@ -53,7 +55,7 @@ func AdmitJobs(ar v1beta1.AdmissionReview) *v1beta1.AdmissionResponse {
 		if err != nil {
 			return ToAdmissionResponse(err)
 		}
-		msg = specDeepEqual(job, oldJob, &reviewResponse)
+		msg = validateJobUpdate(job, oldJob, &reviewResponse)
 		break
 	default:
 		err := fmt.Errorf("expect operation to be 'CREATE' or 'UPDATE'")
@@ -101,6 +103,84 @@ func validateJobSpec(jobSpec v1alpha1.JobSpec, reviewResponse *v1beta1.Admission
 		if err := ValidatePolicies(task.Policies); err != nil {
 			msg = msg + err.Error()
 		}
+
+		// the main container must be one of containers of task
+		if name, found := task.Template.Annotations[v1alpha1.MainContainerKey]; found {
+			if !hasContainer(task.Template.Spec.Containers, name) {
+				msg = msg + fmt.Sprintf(" main container %s is not found in task: %s;", name, task.Name)
+			}
+		}
+
+		if task.MaxFailed != nil && *task.MaxFailed < 0 {
+			msg = msg + fmt.Sprintf(" 'maxFailed' cannot be less than zero in task: %s;", task.Name)
+		}
+	}
+
+	msg = msg + validateTaskDependencies(jobSpec)
+
+	for _, policy := range jobSpec.SuccessPolicies {
+		var task *v1alpha1.TaskSpec
+		for i := range jobSpec.Tasks {
+			if jobSpec.Tasks[i].Name == policy.TaskName {
+				task = &jobSpec.Tasks[i]
+				break
+			}
+		}
+		if task == nil {
+			msg = msg + fmt.Sprintf(" unknown task %s in success policy;", policy.TaskName)
+			continue
+		}
+		if policy.MinSucceeded != nil && (*policy.MinSucceeded <= 0 || *policy.MinSucceeded > task.Replicas) {
+			msg = msg + fmt.Sprintf(" 'minSucceeded' of success policy should be in (0, %d] for task %s;",
+				task.Replicas, task.Name)
+		}
+	}
+
+	if jobSpec.MaxRetry != nil && *jobSpec.MaxRetry < 0 {
+		msg = msg + " 'maxRetry' cannot be less than zero;"
+	}
+
+	if jobSpec.MaxFailed != nil && *jobSpec.MaxFailed < 0 {
+		msg = msg + " 'maxFailed' cannot be less than zero;"
+	}
+
+	if policy := jobSpec.TerminationPolicy; policy != nil {
+		if policy.GracePeriodSeconds != nil && *policy.GracePeriodSeconds < 0 {
+			msg = msg + " 'terminationPolicy.gracePeriodSeconds' cannot be less than zero;"
+		}
+		orderedTasks := map[string]bool{}
+		for _, name := range policy.TaskOrder {
+			if _, found := taskNames[name]; !found {
+				msg = msg + fmt.Sprintf(" unknown task %s in termination policy;", name)
+			} else if orderedTasks[name] {
+				msg = msg + fmt.Sprintf(" duplicated task %s in termination policy;", name)
+			}
+			orderedTasks[name] = true
+		}
+	}
+
+	if policy := jobSpec.RetentionPolicy; policy != nil && policy.MaxFailedPods < 0 {
+		msg = msg + " 'retentionPolicy.maxFailedPods' cannot be less than zero;"
+	}
+
+	if jobSpec.TTLSecondsAfterFinished != nil && *jobSpec.TTLSecondsAfterFinished < 0 {
+		msg = msg + " 'ttlSecondsAfterFinished' cannot be less than zero;"
+	}
+
+	if jobSpec.ActiveDeadlineSeconds != nil && *jobSpec.ActiveDeadlineSeconds <= 0 {
+		msg = msg + " 'activeDeadlineSeconds' must be greater than zero;"
+	}
+
+	if backoff := jobSpec.RestartBackoff; backoff != nil {
+		if backoff.InitialDelay.Duration <= 0 {
+			msg = msg + " 'restartBackoff.initialDelay' must be greater than zero;"
+		}
+		if backoff.Factor < 0 {
+			msg = msg + " 'restartBackoff.factor' cannot be less than zero;"
+		}
+		if backoff.MaxDelay != nil && backoff.MaxDelay.Duration < backoff.InitialDelay.Duration {
+			msg = msg + " 'restartBackoff.maxDelay' should not be less than 'restartBackoff.initialDelay';"
+		}
 	}
 
 	if totalReplicas < jobSpec.MinAvailable {
@@ -114,8 +194,17 @@ func validateJobSpec(jobSpec v1alpha1.JobSpec, reviewResponse *v1beta1.Admission
 	// invalid job plugins
 	if len(jobSpec.Plugins) != 0 {
 		for name := range jobSpec.Plugins {
-			if _, found := plugins.GetPluginBuilder(name); !found {
+			if pb, found := plugins.GetPluginBuilder(name); !found {
 				msg = msg + fmt.Sprintf(" unable to find job plugin: %s", name)
+			} else if validator, ok := pb(vkinterface.PluginClientset{}, jobSpec.Plugins[name]).(vkinterface.PluginValidator); ok {
+				if err := validator.ValidateJobSpec(&jobSpec); err != nil {
+					msg = msg + fmt.Sprintf(" %v;", err)
+				}
+			}
+			for _, dep := range pluginDependencies[name] {
+				if _, found := jobSpec.Plugins[dep]; !found {
+					msg = msg + fmt.Sprintf(" job plugin %s depends on plugin %s;", name, dep)
+				}
 			}
 		}
 	}
@@ -127,11 +216,151 @@ func validateJobSpec(jobSpec v1alpha1.JobSpec, reviewResponse *v1beta1.Admission
 	return msg
 }
 
-func specDeepEqual(newJob v1alpha1.Job, oldJob v1alpha1.Job, reviewResponse *v1beta1.AdmissionResponse) string {
+// pluginDependencies are the plugins which the job plugin depends on,
+// e.g. the mpi plugin depends on the keys of ssh plugin.
+var pluginDependencies = map[string][]string{
+	"mpi": {"ssh"},
+}
+
+func hasContainer(containers []v1.Container, name string) bool {
+	for _, c := range containers {
+		if c.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateJobUpdate only allows to scale a Job by updating `minAvailable` and
+// `replicas` of its tasks; the other fields of job.spec are immutable.
+func validateJobUpdate(newJob v1alpha1.Job, oldJob v1alpha1.Job, reviewResponse *v1beta1.AdmissionResponse) string {
 	var msg string
-	if !reflect.DeepEqual(newJob.Spec, oldJob.Spec) {
+	if len(newJob.Spec.Tasks) != len(oldJob.Spec.Tasks) {
 		reviewResponse.Allowed = false
-		msg = "job.spec is not allowed to modify when update jobs;"
+		return "job.spec.tasks is not allowed to add or remove when update jobs;"
+	}
+
+	var totalReplicas int32
+	for _, task := range newJob.Spec.Tasks {
+		if task.Replicas <= 0 {
+			msg = msg + fmt.Sprintf(" 'replicas' must be greater than 0 in task: %s;", task.Name)
+		}
+		totalReplicas += task.Replicas
+	}
+
+	if newJob.Spec.MinAvailable < 0 {
+		msg = msg + " 'minAvailable' must be greater than or equal to 0;"
+	}
+
+	if totalReplicas < newJob.Spec.MinAvailable {
+		msg = msg + " 'minAvailable' should not be greater than total replicas in tasks;"
+	}
+
+	for _, policy := range newJob.Spec.SuccessPolicies {
+		for _, task := range newJob.Spec.Tasks {
+			if task.Name == policy.TaskName && policy.MinSucceeded != nil && *policy.MinSucceeded > task.Replicas {
+				msg = msg + fmt.Sprintf(" 'minSucceeded' of success policy should not be greater than replicas of task %s;",
+					task.Name)
+			}
+		}
+	}
+
+	// The tasks without dependencies must still satisfy gang-scheduling once scaled.
+	msg = msg + validateTaskDependencies(newJob.Spec)
+
+	// Ignore the scalable fields, the rest of job.spec must be the same.
+	oldSpec := oldJob.Spec.DeepCopy()
+	oldSpec.MinAvailable = newJob.Spec.MinAvailable
+	for i := range oldSpec.Tasks {
+		oldSpec.Tasks[i].Replicas = newJob.Spec.Tasks[i].Replicas
+	}
+
+	if !reflect.DeepEqual(newJob.Spec, *oldSpec) {
+		msg = msg + " job.spec is not allowed to modify when update jobs except 'minAvailable' and 'tasks[].replicas';"
+	}
+
+	if msg != "" {
+		reviewResponse.Allowed = false
+	}
+
+	return msg
+}
+
+func validateTaskDependencies(jobSpec v1alpha1.JobSpec) string {
+	var msg string
+	tasks := map[string]v1alpha1.TaskSpec{}
+	for _, task := range jobSpec.Tasks {
+		tasks[task.Name] = task
+	}
+
+	// The pods of tasks with dependencies are created later, so gang-scheduling
+	// must be satisfied by the tasks without dependencies.
+	var rootReplicas int32
+	var hasDependencies bool
+	for _, task := range jobSpec.Tasks {
+		if len(task.DependsOn) == 0 {
+			rootReplicas += task.Replicas
+		} else {
+			hasDependencies = true
+		}
+
+		for _, dep := range task.DependsOn {
+			if dep.Name == task.Name {
+				msg = msg + fmt.Sprintf(" task %s can not depend on itself;", task.Name)
+				continue
+			}
+			if _, found := tasks[dep.Name]; !found {
+				msg = msg + fmt.Sprintf(" task %s depends on unknown task %s;", task.Name, dep.Name)
+			}
+			switch dep.Condition {
+			case "", v1alpha1.DependencyRunning, v1alpha1.DependencySucceeded:
+			default:
+				msg = msg + fmt.Sprintf(" unknown condition %s of dependency %s in task %s;",
+					dep.Condition, dep.Name, task.Name)
+			}
+		}
+	}
+
+	if len(msg) != 0 || !hasDependencies {
+		return msg
+	}
+
+	if rootReplicas < jobSpec.MinAvailable {
+		msg = msg + " 'minAvailable' should not be greater than total replicas in tasks without dependencies;"
+	}
+
+	// Detect the circular dependencies by depth-first search.
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	states := map[string]int{}
+	var visit func(name string) bool
+	visit = func(name string) bool {
+		switch states[name] {
+		case visiting:
+			return false
+		case visited:
+			return true
+		}
+
+		states[name] = visiting
+		for _, dep := range tasks[name].DependsOn {
+			if !visit(dep.Name) {
+				return false
+			}
+		}
+		states[name] = visited
+
+		return true
+	}
+
+	for _, task := range jobSpec.Tasks {
+		if !visit(task.Name) {
+			msg = msg + fmt.Sprintf(" circular dependency found in task %s;", task.Name)
+			break
+		}
 	}
 
 	return msg
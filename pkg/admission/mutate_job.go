@@ -31,6 +31,11 @@ import (
 	v1alpha1 "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
 )
 
+const (
+	// DefaultMaxRetry is the default number of retries before marking Job failed.
+	DefaultMaxRetry int32 = 3
+)
+
 type patchOperation struct {
 	Op    string      `json:"op"`
 	Path  string      `json:"path"`
@@ -75,6 +80,7 @@ func createPatch(job v1alpha1.Job) ([]byte, error) {
 	var patch []patchOperation
 	patch = append(patch, mutateSpec(job.Spec.Tasks, "/spec/tasks")...)
 	patch = append(patch, mutateMetadata(job.ObjectMeta, "/metadata")...)
+	patch = append(patch, mutateMaxRetry(job.Spec, "/spec/maxRetry")...)
 
 	return json.Marshal(patch)
 }
@@ -96,6 +102,19 @@ func mutateSpec(tasks []v1alpha1.TaskSpec, basePath string) (patch []patchOperat
 	return patch
 }
 
+func mutateMaxRetry(spec v1alpha1.JobSpec, path string) (patch []patchOperation) {
+	// add default maxRetry
+	if spec.MaxRetry == nil {
+		patch = append(patch, patchOperation{
+			Op:    "add",
+			Path:  path,
+			Value: DefaultMaxRetry,
+		})
+	}
+
+	return patch
+}
+
 func mutateMetadata(metadata metav1.ObjectMeta, basePath string) (patch []patchOperation) {
 	if len(metadata.Annotations) == 0 {
 		metadata.Annotations = make(map[string]string)
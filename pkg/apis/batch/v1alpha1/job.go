@@ -19,6 +19,7 @@ package v1alpha1
 import (
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // +genclient
@@ -68,6 +69,109 @@ type JobSpec struct {
 	// Key is plugin name, value is the arguments of the plugin
 	// +optional
 	Plugins map[string][]string `json:"plugins,omitempty" protobuf:"bytes,7,opt,name=plugins"`
+
+	// Specifies the maximum number of retries before marking this Job failed;
+	// 0 means the Job is never restarted.
+	// Defaults to 3.
+	// +optional
+	MaxRetry *int32 `json:"maxRetry,omitempty" protobuf:"varint,8,opt,name=maxRetry"`
+
+	// Specifies the backoff between the restarts of Job.
+	// Default to nil (restart immediately).
+	// +optional
+	RestartBackoff *RestartBackoff `json:"restartBackoff,omitempty" protobuf:"bytes,9,opt,name=restartBackoff"`
+
+	// TTLSecondsAfterFinished limits the lifetime of a Job that has finished
+	// execution (Completed, Terminated, Aborted or Failed). The Job and the
+	// resources it controlled will be deleted once the TTL expires.
+	// Default to nil (the Job won't be deleted automatically).
+	// +optional
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty" protobuf:"varint,10,opt,name=ttlSecondsAfterFinished"`
+
+	// Specifies the duration in seconds relative to the startTime that the Job
+	// may be active before it's terminated; value must be positive integer.
+	// Default to nil (no deadline).
+	// +optional
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty" protobuf:"varint,11,opt,name=activeDeadlineSeconds"`
+
+	// If specified, indicates the Job's priority, it's set to the PodGroup of Job.
+	// The name must be defined by creating a PriorityClass object with that name.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty" protobuf:"bytes,12,opt,name=priorityClassName"`
+
+	// Specifies when the Job is succeeded; the Job is succeeded once any of
+	// the policies is satisfied, and the remaining pods are killed.
+	// Default to nil (the Job is completed once all pods are finished).
+	// +optional
+	SuccessPolicies []SuccessPolicy `json:"successPolicies,omitempty" protobuf:"bytes,13,rep,name=successPolicies"`
+
+	// Specifies the number of failed pods the Job tolerates; the policies of
+	// failed pods, e.g. PodFailed, are not fired until it is exceeded.
+	// Default to nil (no failed pods are tolerated unless the task tolerates them).
+	// +optional
+	MaxFailed *int32 `json:"maxFailed,omitempty" protobuf:"varint,14,opt,name=maxFailed"`
+
+	// Specifies how the pods of Job are killed, e.g. when the Job is restarted,
+	// aborted, terminated or completed.
+	// Default to nil (all pods are killed together with their own grace period).
+	// +optional
+	TerminationPolicy *TerminationPolicy `json:"terminationPolicy,omitempty" protobuf:"bytes,15,opt,name=terminationPolicy"`
+
+	// Specifies the failed pods to retain for debugging when the Job is killed,
+	// e.g. restarted; the retained pods are deleted together with the Job.
+	// Default to nil (all pods are deleted).
+	// +optional
+	RetentionPolicy *RetentionPolicy `json:"retentionPolicy,omitempty" protobuf:"bytes,16,opt,name=retentionPolicy"`
+}
+
+// RetentionPolicy specifies the failed pods to retain for debugging.
+type RetentionPolicy struct {
+	// The maximal number of failed pods to retain for each task, the oldest
+	// retained pods are deleted once it's exceeded.
+	MaxFailedPods int32 `json:"maxFailedPods" protobuf:"varint,1,opt,name=maxFailedPods"`
+}
+
+// TerminationPolicy specifies the grace period and the order to kill the pods of Job.
+type TerminationPolicy struct {
+	// The grace period in seconds to kill the pods of Job, it overrides the
+	// grace period of pods.
+	// Default to nil (the grace period of pods is used).
+	// +optional
+	GracePeriodSeconds *int64 `json:"gracePeriodSeconds,omitempty" protobuf:"varint,1,opt,name=gracePeriodSeconds"`
+
+	// The order of tasks to kill; the pods of a task are not killed until the
+	// pods of the tasks before it are gone. The tasks not in the list are killed
+	// first, e.g. ["chief"] kills the other tasks before the chief.
+	// Default to nil (all tasks are killed together).
+	// +optional
+	TaskOrder []string `json:"taskOrder,omitempty" protobuf:"bytes,2,rep,name=taskOrder"`
+}
+
+// SuccessPolicy specifies the number of succeeded pods of a task to make the Job succeeded.
+type SuccessPolicy struct {
+	// The name of the task.
+	TaskName string `json:"taskName" protobuf:"bytes,1,opt,name=taskName"`
+
+	// The minimal number of succeeded pods of the task.
+	// Default to the replicas of the task.
+	// +optional
+	MinSucceeded *int32 `json:"minSucceeded,omitempty" protobuf:"varint,2,opt,name=minSucceeded"`
+}
+
+// RestartBackoff specifies the exponential backoff between the restarts of Job.
+type RestartBackoff struct {
+	// The delay before the first restart of Job.
+	InitialDelay metav1.Duration `json:"initialDelay,omitempty" protobuf:"bytes,1,opt,name=initialDelay"`
+
+	// The multiplier applied to the delay for each following restart.
+	// Defaults to 2.
+	// +optional
+	Factor int32 `json:"factor,omitempty" protobuf:"bytes,2,opt,name=factor"`
+
+	// The maximum delay between two restarts.
+	// Default to nil (no limit).
+	// +optional
+	MaxDelay *metav1.Duration `json:"maxDelay,omitempty" protobuf:"bytes,3,opt,name=maxDelay"`
 }
 
 // VolumeSpec defines the specification of Volume, e.g. PVC
@@ -85,6 +189,8 @@ type JobEvent string
 const (
 	CommandIssued JobEvent = "CommandIssued"
 	PluginError   JobEvent = "PluginError"
+	// PodFailed is recorded when a policy is fired by the failure of pod
+	PodFailed JobEvent = "PodFailed"
 )
 
 // Event represent the phase of Job, e.g. pod-failed.
@@ -97,10 +203,19 @@ const (
 	PodFailedEvent Event = "PodFailed"
 	// PodEvictedEvent is triggered if Pod was deleted
 	PodEvictedEvent Event = "PodEvicted"
+	// PodOOMKilledEvent is triggered if Pod was failed as its container was OOM killed
+	PodOOMKilledEvent Event = "PodOOMKilled"
+	// NodeLostEvent is triggered if Pod was failed or deleted as its node was lost
+	NodeLostEvent Event = "NodeLost"
+	// PodPreemptedEvent is triggered if Pod was failed as it was preempted by kubelet
+	PodPreemptedEvent Event = "PodPreempted"
 	// These below are several events can lead to job 'Unknown'
 	// 1. Task Unschedulable, this is triggered when part of
 	//    pods can't be scheduled while some are already running in gang-scheduling case.
 	JobUnknownEvent Event = "Unknown"
+	// JobUnschedulableEvent is triggered if the PodGroup of Job is unschedulable,
+	// e.g. there's not enough resources for the minimal available pods of Job.
+	JobUnschedulableEvent Event = "JobUnschedulable"
 
 	// OutOfSyncEvent is triggered if Pod/Job were updated
 	OutOfSyncEvent Event = "OutOfSync"
@@ -157,6 +272,49 @@ type LifecyclePolicy struct {
 	// Default to nil (take action immediately).
 	// +optional
 	Timeout *metav1.Duration `json:"timeout,omitempty" protobuf:"bytes,3,opt,name=timeout"`
+
+	// The Events recorded by scheduler; the controller takes actions
+	// if any of these Events happened.
+	// Note: events can not be specified together with exit codes.
+	// +optional
+	Events []Event `json:"events,omitempty" protobuf:"bytes,4,rep,name=events"`
+
+	// The exit codes of the pod container, controller will take action
+	// if the exit code is matched.
+	// Note: exit codes can not be specified together with events.
+	// +optional
+	ExitCodes *ExitCodeRequirement `json:"exitCodes,omitempty" protobuf:"bytes,5,opt,name=exitCodes"`
+}
+
+// ExitCodeOperator is the relationship between the exit code and the set of codes.
+type ExitCodeOperator string
+
+const (
+	// ExitCodeOpIn matches the exit code in the set of codes.
+	ExitCodeOpIn ExitCodeOperator = "In"
+	// ExitCodeOpNotIn matches the non-zero exit code out of the set of codes.
+	ExitCodeOpNotIn ExitCodeOperator = "NotIn"
+)
+
+// ExitCodeRequirement specifies a set of exit codes with an operator.
+type ExitCodeRequirement struct {
+	// Operator represents the relationship between the exit code and the set of codes.
+	// One of "In", "NotIn".
+	Operator ExitCodeOperator `json:"operator" protobuf:"bytes,1,opt,name=operator,casttype=ExitCodeOperator"`
+
+	// The exit codes in the set.
+	// +optional
+	Values []int32 `json:"values,omitempty" protobuf:"varint,2,rep,name=values"`
+
+	// The ranges of exit codes in the set.
+	// +optional
+	Ranges []ExitCodeRange `json:"ranges,omitempty" protobuf:"bytes,3,rep,name=ranges"`
+}
+
+// ExitCodeRange is a range of exit codes, both ends are included.
+type ExitCodeRange struct {
+	Min int32 `json:"min" protobuf:"varint,1,opt,name=min"`
+	Max int32 `json:"max" protobuf:"varint,2,opt,name=max"`
 }
 
 // TaskSpec specifies the task specification of Job
@@ -174,6 +332,39 @@ type TaskSpec struct {
 	// Specifies the lifecycle of task
 	// +optional
 	Policies []LifecyclePolicy `json:"policies,omitempty" protobuf:"bytes,4,opt,name=policies"`
+
+	// Specifies the tasks that this task depends on; the pods of this task
+	// will not be created until all of the dependencies are satisfied.
+	// +optional
+	DependsOn []TaskDependency `json:"dependsOn,omitempty" protobuf:"bytes,5,rep,name=dependsOn"`
+
+	// Specifies the number of failed pods the task tolerates; the policies of
+	// failed pods, e.g. PodFailed, are not fired until it is exceeded.
+	// Default to nil (no failed pods are tolerated unless the Job tolerates them).
+	// +optional
+	MaxFailed *int32 `json:"maxFailed,omitempty" protobuf:"varint,6,opt,name=maxFailed"`
+}
+
+// DependencyCondition is the condition of a depended task.
+type DependencyCondition string
+
+const (
+	// DependencyRunning means all pods of the depended task are running (or succeeded).
+	DependencyRunning DependencyCondition = "Running"
+	// DependencySucceeded means all pods of the depended task are succeeded.
+	DependencySucceeded DependencyCondition = "Succeeded"
+)
+
+// TaskDependency specifies a task that the task depends on.
+type TaskDependency struct {
+	// Name specifies the name of the depended task.
+	Name string `json:"name" protobuf:"bytes,1,opt,name=name"`
+
+	// The condition of the depended task to satisfy the dependency.
+	// One of "Running", "Succeeded".
+	// Default to "Running".
+	// +optional
+	Condition DependencyCondition `json:"condition,omitempty" protobuf:"bytes,2,opt,name=condition"`
 }
 
 type JobPhase string
@@ -197,6 +388,8 @@ const (
 	Terminating JobPhase = "Terminating"
 	// Terminated is the phase that the job is finished unexpected, e.g. events
 	Terminated JobPhase = "Terminated"
+	// Failed is the phase that the job is restarted failed reached the maximum number of retries.
+	Failed JobPhase = "Failed"
 )
 
 // JobState contains details for the current state of the job.
@@ -246,6 +439,157 @@ type JobStatus struct {
 	Version int32 `json:"version,omitempty" protobuf:"bytes,8,opt,name=version"`
 	// The resources that controlled by this job, e.g. Service, ConfigMap
 	ControlledResources map[string]string `json:"controlledResources,omitempty" protobuf:"bytes,8,opt,name=controlledResources"`
+	// The actions which are waiting for the timeout of their LifecyclePolicy
+	// +optional
+	DelayedActions []DelayedAction `json:"delayedActions,omitempty" protobuf:"bytes,9,rep,name=delayedActions"`
+	// The number of Job retries.
+	// +optional
+	RetryCount int32 `json:"retryCount,omitempty" protobuf:"bytes,10,opt,name=retryCount"`
+	// The time after which the pods of the restarted Job will be re-created.
+	// +optional
+	NextRestartTime *metav1.Time `json:"nextRestartTime,omitempty" protobuf:"bytes,11,opt,name=nextRestartTime"`
+	// The time at which the Job was first synced by controller, it's the
+	// start of 'activeDeadlineSeconds'.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty" protobuf:"bytes,13,opt,name=startTime"`
+	// The time at which the Job finished, e.g. Completed or Terminated.
+	// +optional
+	FinishTime *metav1.Time `json:"finishTime,omitempty" protobuf:"bytes,12,opt,name=finishTime"`
+
+	// The latest phase transitions of Job, the latest one is at the end.
+	// +optional
+	Conditions []JobCondition `json:"conditions,omitempty" protobuf:"bytes,14,rep,name=conditions"`
+
+	// The status of pods of each task, keyed by task name.
+	// +optional
+	TaskStatuses map[string]TaskStatus `json:"taskStatuses,omitempty" protobuf:"bytes,15,rep,name=taskStatuses"`
+
+	// The current version of tasks, keyed by task name; it's bumped when
+	// the task is restarted.
+	// +optional
+	TaskVersions map[string]int32 `json:"taskVersions,omitempty" protobuf:"bytes,16,rep,name=taskVersions"`
+
+	// The number of failed pods which are tolerated by 'maxFailed'.
+	// +optional
+	Tolerated int32 `json:"tolerated,omitempty" protobuf:"bytes,17,opt,name=tolerated"`
+
+	// The latest failures of pods which fired policies, the latest one is
+	// at the end.
+	// +optional
+	LastFailures []PodFailure `json:"lastFailures,omitempty" protobuf:"bytes,18,rep,name=lastFailures"`
+
+	// The generation of Job observed by controller; the plugins are notified
+	// once the spec of Job is updated since then.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty" protobuf:"varint,19,opt,name=observedGeneration"`
+}
+
+// PodFailure records the failure of pod which fired a policy.
+type PodFailure struct {
+	// The name of the failed pod.
+	PodName string `json:"podName" protobuf:"bytes,1,opt,name=podName"`
+
+	// The name of the task of the failed pod.
+	TaskName string `json:"taskName" protobuf:"bytes,2,opt,name=taskName"`
+
+	// The name of the container which made the pod failed.
+	// +optional
+	ContainerName string `json:"containerName,omitempty" protobuf:"bytes,3,opt,name=containerName"`
+
+	// The exit code of the container.
+	// +optional
+	ExitCode int32 `json:"exitCode,omitempty" protobuf:"varint,4,opt,name=exitCode"`
+
+	// The termination reason of the container or the pod, e.g. OOMKilled.
+	// +optional
+	Reason string `json:"reason,omitempty" protobuf:"bytes,5,opt,name=reason"`
+
+	// The last lines of the termination message or log of the container.
+	// +optional
+	Message string `json:"message,omitempty" protobuf:"bytes,6,opt,name=message"`
+
+	// The event triggered by the failure.
+	Event Event `json:"event" protobuf:"bytes,7,opt,name=event,casttype=Event"`
+
+	// The action taken for the failure.
+	Action Action `json:"action" protobuf:"bytes,8,opt,name=action,casttype=Action"`
+
+	// The time at which the action was taken.
+	Time metav1.Time `json:"time" protobuf:"bytes,9,opt,name=time"`
+
+	// The UID of the failed pod, it tells the failures of the re-created
+	// pods with the same name apart.
+	// +optional
+	PodUID types.UID `json:"podUID,omitempty" protobuf:"bytes,10,opt,name=podUID,casttype=k8s.io/apimachinery/pkg/types.UID"`
+}
+
+// TaskStatus represents the number of pods of a task in each phase.
+type TaskStatus struct {
+	// The number of pending pods.
+	// +optional
+	Pending int32 `json:"pending,omitempty" protobuf:"bytes,1,opt,name=pending"`
+
+	// The number of running pods.
+	// +optional
+	Running int32 `json:"running,omitempty" protobuf:"bytes,2,opt,name=running"`
+
+	// The number of pods which reached phase Succeeded.
+	// +optional
+	Succeeded int32 `json:"succeeded,omitempty" protobuf:"bytes,3,opt,name=succeeded"`
+
+	// The number of pods which reached phase Failed.
+	// +optional
+	Failed int32 `json:"failed,omitempty" protobuf:"bytes,4,opt,name=failed"`
+
+	// The number of pods which are terminating.
+	// +optional
+	Terminating int32 `json:"terminating,omitempty" protobuf:"bytes,5,opt,name=terminating"`
+
+	// The number of failed pods which are tolerated by 'maxFailed'.
+	// +optional
+	Tolerated int32 `json:"tolerated,omitempty" protobuf:"bytes,6,opt,name=tolerated"`
+}
+
+// JobCondition records a phase transition of Job.
+type JobCondition struct {
+	// The phase which the Job transited to.
+	Type JobPhase `json:"type" protobuf:"bytes,1,opt,name=type,casttype=JobPhase"`
+
+	// Status of the condition, one of True, False, Unknown.
+	Status v1.ConditionStatus `json:"status" protobuf:"bytes,2,opt,name=status,casttype=k8s.io/api/core/v1.ConditionStatus"`
+
+	// Unique, one-word, CamelCase reason for the transition.
+	// +optional
+	Reason string `json:"reason,omitempty" protobuf:"bytes,3,opt,name=reason"`
+
+	// Human-readable message indicating details about the transition.
+	// +optional
+	Message string `json:"message,omitempty" protobuf:"bytes,4,opt,name=message"`
+
+	// The time at which the Job transited to the phase.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty" protobuf:"bytes,5,opt,name=lastTransitionTime"`
+}
+
+// DelayedAction is an action that the controller will take once the
+// timeout of the matched LifecyclePolicy expires.
+type DelayedAction struct {
+	// The action that will be taken.
+	Action Action `json:"action,omitempty" protobuf:"bytes,1,opt,name=action"`
+
+	// The event which triggered the action.
+	Event Event `json:"event,omitempty" protobuf:"bytes,2,opt,name=event"`
+
+	// The name of the task whose pod triggered the action.
+	// +optional
+	TaskName string `json:"taskName,omitempty" protobuf:"bytes,3,opt,name=taskName"`
+
+	// The name of the pod which triggered the action.
+	// +optional
+	PodName string `json:"podName,omitempty" protobuf:"bytes,4,opt,name=podName"`
+
+	// The time at which the action will be taken.
+	ScheduledTime metav1.Time `json:"scheduledTime,omitempty" protobuf:"bytes,5,opt,name=scheduledTime"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -22,4 +22,15 @@ const (
 	JobNamespaceKey = "volcano.sh/job-namespace"
 	DefaultTaskSpec = "default"
 	JobVersion      = "volcano.sh/job-version"
+	TaskVersion     = "volcano.sh/task-version"
+	TaskIndex       = "volcano.sh/task-index"
+	// MainContainerKey is the annotation of pod template to specify the container
+	// whose exit code is used by LifecyclePolicy, e.g. the one besides sidecars.
+	MainContainerKey = "volcano.sh/main-container"
+	// RetainedPodKey is the label of the failed pod which is retained for debugging,
+	// the retained pod is not controlled by Job any more.
+	RetainedPodKey = "volcano.sh/retained-pod"
+	// ScaledDownPodKey is the annotation of the pod which is deleted by controller as it's
+	// not desired by Job any more, e.g. scaled down; its deletion is not a failure of Job.
+	ScaledDownPodKey = "volcano.sh/scaled-down"
 )
@@ -17,6 +17,9 @@ limitations under the License.
 package helpers
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/golang/glog"
 
 	"k8s.io/api/core/v1"
@@ -32,6 +35,10 @@ import (
 	vkcorev1 "volcano.sh/volcano/pkg/apis/bus/v1alpha1"
 )
 
+// ServicePlaceholderPort is the name of port of the Service of Job which is
+// created without any port to open.
+const ServicePlaceholderPort = "placeholder-volcano"
+
 var JobKind = vkbatchv1.SchemeGroupVersion.WithKind("Job")
 var CommandKind = vkcorev1.SchemeGroupVersion.WithKind("Command")
 
@@ -93,6 +100,39 @@ func CreateConfigMapIfNotExist(job *vkv1.Job, kubeClients *kubernetes.Clientset,
 	return nil
 }
 
+// UpdateConfigMapIfChanged sets the given keys of the ConfigMap if their values are changed;
+// the other keys of ConfigMap are kept, e.g. the ones published by other plugins into it.
+func UpdateConfigMapIfChanged(job *vkv1.Job, kubeClients *kubernetes.Clientset, data map[string]string, cmName string) error {
+	cm, err := kubeClients.CoreV1().ConfigMaps(job.Namespace).Get(cmName, metav1.GetOptions{})
+	if err != nil {
+		glog.V(3).Infof("Failed to get Configmap for Job <%s/%s>: %v",
+			job.Namespace, job.Name, err)
+		return err
+	}
+
+	changed := false
+	if cm.Data == nil {
+		cm.Data = make(map[string]string, len(data))
+	}
+	for key, value := range data {
+		if old, found := cm.Data[key]; !found || old != value {
+			cm.Data[key] = value
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	if _, err := kubeClients.CoreV1().ConfigMaps(job.Namespace).Update(cm); err != nil {
+		glog.V(3).Infof("Failed to update ConfigMap for Job <%s/%s>: %v",
+			job.Namespace, job.Name, err)
+		return err
+	}
+
+	return nil
+}
+
 func DeleteConfigmap(job *vkv1.Job, kubeClients *kubernetes.Clientset, cmName string) error {
 	if err := kubeClients.CoreV1().ConfigMaps(job.Namespace).Delete(cmName, nil); err != nil {
 		if !apierrors.IsNotFound(err) {
@@ -104,3 +144,76 @@ func DeleteConfigmap(job *vkv1.Job, kubeClients *kubernetes.Clientset, cmName st
 
 	return nil
 }
+
+// AddServicePortsIfNotExist opens the ports on the headless Service of Job, which is named after
+// the Job; the placeholder port is replaced by them. The ports already opened are skipped, and
+// the port whose name is used by another one is renamed by DefaultServicePortName.
+func AddServicePortsIfNotExist(job *vkv1.Job, kubeClients *kubernetes.Clientset, ports []v1.ServicePort) error {
+	svc, err := kubeClients.CoreV1().Services(job.Namespace).Get(job.Name, metav1.GetOptions{})
+	if err != nil {
+		glog.V(3).Infof("Failed to get Service for Job <%s/%s>: %v",
+			job.Namespace, job.Name, err)
+		return err
+	}
+
+	svcPorts := make([]v1.ServicePort, 0, len(svc.Spec.Ports)+len(ports))
+	for _, p := range svc.Spec.Ports {
+		if p.Name != ServicePlaceholderPort {
+			svcPorts = append(svcPorts, p)
+		}
+	}
+
+	changed := false
+	for _, port := range ports {
+		if hasServicePort(svcPorts, port) {
+			continue
+		}
+		if hasServicePortName(svcPorts, port.Name) {
+			port.Name = DefaultServicePortName(port)
+			if hasServicePortName(svcPorts, port.Name) {
+				glog.Warningf("Skip port %d/%s of Job <%s/%s>, its name %s is used",
+					port.Port, port.Protocol, job.Namespace, job.Name, port.Name)
+				continue
+			}
+		}
+		svcPorts = append(svcPorts, port)
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	svc.Spec.Ports = svcPorts
+	if _, err := kubeClients.CoreV1().Services(job.Namespace).Update(svc); err != nil {
+		glog.V(3).Infof("Failed to update Service for Job <%s/%s>: %v",
+			job.Namespace, job.Name, err)
+		return err
+	}
+
+	return nil
+}
+
+// DefaultServicePortName names the port after its protocol and port, e.g. tcp-2222.
+func DefaultServicePortName(port v1.ServicePort) string {
+	return fmt.Sprintf("%s-%d", strings.ToLower(string(port.Protocol)), port.Port)
+}
+
+func hasServicePort(ports []v1.ServicePort, port v1.ServicePort) bool {
+	for _, p := range ports {
+		if p.Port == port.Port && p.Protocol == port.Protocol {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasServicePortName(ports []v1.ServicePort, name string) bool {
+	for _, p := range ports {
+		if p.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
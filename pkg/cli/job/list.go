@@ -19,6 +19,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
+
 	"volcano.sh/volcano/pkg/apis/batch/v1alpha1"
 
 	"github.com/spf13/cobra"
@@ -44,6 +46,7 @@ const (
 	Running   string = "Running"
 	Succeeded string = "Succeeded"
 	Failed    string = "Failed"
+	Tasks     string = "Tasks"
 )
 
 var listJobFlags = &listFlags{}
@@ -76,8 +79,8 @@ func ListJobs() error {
 }
 
 func PrintJobs(jobs *v1alpha1.JobList, writer io.Writer) {
-	_, err := fmt.Fprintf(writer, "%-25s%-25s%-12s%-12s%-6s%-10s%-10s%-12s%-10s\n",
-		Name, Creation, Phase, Replicas, Min, Pending, Running, Succeeded, Failed)
+	_, err := fmt.Fprintf(writer, "%-25s%-25s%-12s%-12s%-6s%-10s%-10s%-12s%-10s%s\n",
+		Name, Creation, Phase, Replicas, Min, Pending, Running, Succeeded, Failed, Tasks)
 	if err != nil {
 		fmt.Printf("Failed to print list command result: %s.\n", err)
 	}
@@ -86,11 +89,24 @@ func PrintJobs(jobs *v1alpha1.JobList, writer io.Writer) {
 		for _, ts := range job.Spec.Tasks {
 			replicas += ts.Replicas
 		}
-		_, err = fmt.Fprintf(writer, "%-25s%-25s%-12s%-12d%-6d%-10d%-10d%-12d%-10d\n",
+		_, err = fmt.Fprintf(writer, "%-25s%-25s%-12s%-12d%-6d%-10d%-10d%-12d%-10d%s\n",
 			job.Name, job.CreationTimestamp.Format("2006-01-02 15:04:05"), job.Status.State.Phase, replicas,
-			job.Status.MinAvailable, job.Status.Pending, job.Status.Running, job.Status.Succeeded, job.Status.Failed)
+			job.Status.MinAvailable, job.Status.Pending, job.Status.Running, job.Status.Succeeded, job.Status.Failed,
+			taskSummary(&job))
 		if err != nil {
 			fmt.Printf("Failed to print list command result: %s.\n", err)
 		}
 	}
 }
+
+// taskSummary returns the running pods and replicas of each task of job,
+// e.g. "ps:1/1,worker:2/4"; see 'job view' for the details of tasks.
+func taskSummary(job *v1alpha1.Job) string {
+	var summary []string
+	for _, ts := range job.Spec.Tasks {
+		summary = append(summary, fmt.Sprintf("%s:%d/%d",
+			ts.Name, job.Status.TaskStatuses[ts.Name].Running, ts.Replicas))
+	}
+
+	return strings.Join(summary, ",")
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"volcano.sh/volcano/pkg/apis/batch/v1alpha1"
+	"volcano.sh/volcano/pkg/client/clientset/versioned"
+)
+
+type viewFlags struct {
+	commonFlags
+
+	Namespace string
+	JobName   string
+}
+
+const (
+	Task        string = "Task"
+	Terminating string = "Terminating"
+	Tolerated   string = "Tolerated"
+	Pod         string = "Pod"
+	Event       string = "Event"
+	Action      string = "Action"
+	ExitCode    string = "ExitCode"
+	Reason      string = "Reason"
+	Time        string = "Time"
+)
+
+var viewJobFlags = &viewFlags{}
+
+func InitViewFlags(cmd *cobra.Command) {
+	initFlags(cmd, &viewJobFlags.commonFlags)
+
+	cmd.Flags().StringVarP(&viewJobFlags.Namespace, "namespace", "", "default", "the namespace of job")
+	cmd.Flags().StringVarP(&viewJobFlags.JobName, "name", "n", "", "the name of job")
+}
+
+func ViewJob() error {
+	config, err := buildConfig(viewJobFlags.Master, viewJobFlags.Kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	if viewJobFlags.JobName == "" {
+		return fmt.Errorf("job name is mandatory to view a particular job")
+	}
+
+	jobClient := versioned.NewForConfigOrDie(config)
+	job, err := jobClient.BatchV1alpha1().Jobs(viewJobFlags.Namespace).Get(viewJobFlags.JobName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	PrintJob(job, os.Stdout)
+
+	return nil
+}
+
+// PrintJob prints the summary of job and the status of each of its tasks.
+func PrintJob(job *v1alpha1.Job, writer io.Writer) {
+	replicas := int32(0)
+	for _, ts := range job.Spec.Tasks {
+		replicas += ts.Replicas
+	}
+
+	_, err := fmt.Fprintf(writer, "%-25s%-25s%-12s%-12s%-6s%-10s%-10s%-12s%-10s\n",
+		Name, Creation, Phase, Replicas, Min, Pending, Running, Succeeded, Failed)
+	if err != nil {
+		fmt.Printf("Failed to print view command result: %s.\n", err)
+	}
+	_, err = fmt.Fprintf(writer, "%-25s%-25s%-12s%-12d%-6d%-10d%-10d%-12d%-10d\n",
+		job.Name, job.CreationTimestamp.Format("2006-01-02 15:04:05"), job.Status.State.Phase, replicas,
+		job.Status.MinAvailable, job.Status.Pending, job.Status.Running, job.Status.Succeeded, job.Status.Failed)
+	if err != nil {
+		fmt.Printf("Failed to print view command result: %s.\n", err)
+	}
+
+	_, err = fmt.Fprintf(writer, "\n%-25s%-12s%-10s%-10s%-12s%-10s%-12s%-10s\n",
+		Task, Replicas, Pending, Running, Succeeded, Failed, Terminating, Tolerated)
+	if err != nil {
+		fmt.Printf("Failed to print view command result: %s.\n", err)
+	}
+	for _, ts := range job.Spec.Tasks {
+		status := job.Status.TaskStatuses[ts.Name]
+		_, err = fmt.Fprintf(writer, "%-25s%-12d%-10d%-10d%-12d%-10d%-12d%-10d\n",
+			ts.Name, ts.Replicas, status.Pending, status.Running, status.Succeeded, status.Failed,
+			status.Terminating, status.Tolerated)
+		if err != nil {
+			fmt.Printf("Failed to print view command result: %s.\n", err)
+		}
+	}
+
+	if len(job.Status.LastFailures) == 0 {
+		return
+	}
+
+	_, err = fmt.Fprintf(writer, "\nLast Failures:\n%-25s%-35s%-15s%-15s%-10s%-15s%-20s\n",
+		Task, Pod, Event, Action, ExitCode, Reason, Time)
+	if err != nil {
+		fmt.Printf("Failed to print view command result: %s.\n", err)
+	}
+	for _, failure := range job.Status.LastFailures {
+		_, err = fmt.Fprintf(writer, "%-25s%-35s%-15s%-15s%-10d%-15s%-20s\n",
+			failure.TaskName, failure.PodName, failure.Event, failure.Action, failure.ExitCode, failure.Reason,
+			failure.Time.Format("2006-01-02 15:04:05"))
+		if err != nil {
+			fmt.Printf("Failed to print view command result: %s.\n", err)
+		}
+		if len(failure.Message) != 0 {
+			_, err = fmt.Fprintf(writer, "%s\n", failure.Message)
+			if err != nil {
+				fmt.Printf("Failed to print view command result: %s.\n", err)
+			}
+		}
+	}
+}
@@ -131,16 +131,25 @@ type Request struct {
 	Namespace string
 	JobName   string
 	TaskName  string
+	PodName   string
 
-	Event      v1alpha1.Event
+	Event v1alpha1.Event
+	// BaseEvent is the general event of the finer-grained Event, e.g. PodEvicted
+	// for NodeLost of a deleted pod; its policies are applied if no policy matches Event.
+	BaseEvent  v1alpha1.Event
 	ExitCode   int32
 	Action     v1alpha1.Action
 	JobVersion int32
+	// TaskVersion is the version of task when the pod was created.
+	TaskVersion int32
+
+	// Delayed is true if the request is re-queued after the timeout of a LifecyclePolicy.
+	Delayed bool
 }
 
 func (r Request) String() string {
 	return fmt.Sprintf(
-		"Job: %s/%s, Task:%s, Event:%s, ExitCode:%d, Action:%s, JobVersion: %d",
-		r.Namespace, r.JobName, r.TaskName, r.Event, r.ExitCode, r.Action, r.JobVersion)
+		"Job: %s/%s, Task:%s, Pod:%s, Event:%s, ExitCode:%d, Action:%s, JobVersion: %d, TaskVersion: %d, Delayed: %t",
+		r.Namespace, r.JobName, r.TaskName, r.PodName, r.Event, r.ExitCode, r.Action, r.JobVersion, r.TaskVersion, r.Delayed)
 
 }
@@ -18,6 +18,7 @@ package cache
 
 import (
 	"fmt"
+	"strconv"
 	"sync"
 
 	"github.com/golang/glog"
@@ -135,13 +136,28 @@ func (jc *jobCache) Update(obj *v1alpha1.Job) error {
 	key := JobKey(obj)
 	if job, found := jc.jobs[key]; !found {
 		return fmt.Errorf("failed to find job <%v>", key)
-	} else {
+	} else if job.Job == nil || !olderThan(obj, job.Job) {
 		job.Job = obj
 	}
 
 	return nil
 }
 
+// olderThan returns whether the Job is older than the cached one, e.g. the Job of
+// informer event is older than the status updated by controller since then.
+func olderThan(job, cached *v1alpha1.Job) bool {
+	version, err := strconv.ParseUint(job.ResourceVersion, 10, 64)
+	if err != nil {
+		return false
+	}
+	cachedVersion, err := strconv.ParseUint(cached.ResourceVersion, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	return version < cachedVersion
+}
+
 func (jc *jobCache) Delete(obj *v1alpha1.Job) error {
 	jc.Lock()
 	defer jc.Unlock()
@@ -17,16 +17,26 @@ limitations under the License.
 package helpers
 
 import (
+	"fmt"
 	"strings"
 
 	"k8s.io/api/core/v1"
+
+	vkv1 "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
 )
 
 const (
 	TaskNameFmt = "%s-%s-%d"
 )
 
+// GetTaskIndex returns the index of pod in its task; the name of pod may be
+// suffixed by versions if the failed pods are retained, so the annotation is
+// preferred.
 func GetTaskIndex(pod *v1.Pod) string {
+	if index, found := pod.Annotations[vkv1.TaskIndex]; found {
+		return index
+	}
+
 	num := strings.Split(pod.Name, "-")
 	if len(num) >= 3 {
 		return num[len(num)-1]
@@ -34,3 +44,43 @@ func GetTaskIndex(pod *v1.Pod) string {
 
 	return ""
 }
+
+// MakeDomainName returns the DNS domain of the pod of task, e.g. hostname.subdomain;
+// the name of pod and the Job are used if not specified in the template.
+func MakeDomainName(ts vkv1.TaskSpec, job *vkv1.Job, index int) string {
+	hostName := ts.Template.Spec.Hostname
+	subdomain := ts.Template.Spec.Subdomain
+	if len(hostName) == 0 {
+		hostName = fmt.Sprintf(TaskNameFmt, job.Name, ts.Name, index)
+	}
+	if len(subdomain) == 0 {
+		subdomain = job.Name
+	}
+	return hostName + "." + subdomain
+}
+
+// SetDefaultDomain sets the name of pod and the Job as its hostname and subdomain if
+// not specified, i.e. the DNS domain returned by MakeDomainName, which is resolved
+// by the headless Service of Job.
+func SetDefaultDomain(pod *v1.Pod, job *vkv1.Job) {
+	if len(pod.Spec.Hostname) == 0 {
+		pod.Spec.Hostname = pod.Name
+	}
+	if len(pod.Spec.Subdomain) == 0 {
+		pod.Spec.Subdomain = job.Name
+	}
+}
+
+// MainContainerIndex returns the index of the container specified by the main
+// container annotation of pod, or the first container, e.g. besides sidecars.
+func MainContainerIndex(pod *v1.Pod) int {
+	if name, found := pod.Annotations[vkv1.MainContainerKey]; found {
+		for i, c := range pod.Spec.Containers {
+			if c.Name == name {
+				return i
+			}
+		}
+	}
+
+	return 0
+}
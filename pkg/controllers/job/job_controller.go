@@ -25,6 +25,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	corelisters "k8s.io/client-go/listers/core/v1"
+	schedulinglisters "k8s.io/client-go/listers/scheduling/v1beta1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
@@ -35,6 +36,7 @@ import (
 	kbinfo "github.com/kubernetes-sigs/kube-batch/pkg/client/informers/externalversions/scheduling/v1alpha1"
 	kblister "github.com/kubernetes-sigs/kube-batch/pkg/client/listers/scheduling/v1alpha1"
 
+	vkv1 "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
 	vkver "volcano.sh/volcano/pkg/client/clientset/versioned"
 	vkscheme "volcano.sh/volcano/pkg/client/clientset/versioned/scheme"
 	vkinfoext "volcano.sh/volcano/pkg/client/informers/externalversions"
@@ -81,9 +83,18 @@ type Controller struct {
 	cmdLister vkcorelister.CommandLister
 	cmdSynced func() bool
 
+	// A store of priority classes
+	pcLister schedulinglisters.PriorityClassLister
+	pcSynced func() bool
+
+	// A store of nodes
+	nodeLister corelisters.NodeLister
+	nodeSynced func() bool
+
 	// queue that need to sync up
 	queue        workqueue.RateLimitingInterface
 	commandQueue workqueue.RateLimitingInterface
+	ttlQueue     workqueue.RateLimitingInterface
 	cache        jobcache.Cache
 	//Job Event recorder
 	recorder record.EventRecorder
@@ -107,15 +118,15 @@ func NewJobController(config *rest.Config) *Controller {
 		kbClients:    kbver.NewForConfigOrDie(config),
 		queue:        workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
 		commandQueue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		ttlQueue:     workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
 		cache:        jobcache.New(),
 		recorder:     recorder,
 	}
 
 	cc.jobInformer = vkinfoext.NewSharedInformerFactory(cc.vkClients, 0).Batch().V1alpha1().Jobs()
 	cc.jobInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc: cc.addJob,
-		// TODO: enable this until we find an appropriate way.
-		// UpdateFunc: cc.updateJob,
+		AddFunc:    cc.addJob,
+		UpdateFunc: cc.updateJob,
 		DeleteFunc: cc.deleteJob,
 	})
 	cc.jobLister = cc.jobInformer.Lister()
@@ -147,6 +158,14 @@ func NewJobController(config *rest.Config) *Controller {
 	cc.svcLister = svcInformer.Lister()
 	cc.svcSynced = svcInformer.Informer().HasSynced
 
+	pcInformer := cc.sharedInformers.Scheduling().V1beta1().PriorityClasses()
+	cc.pcLister = pcInformer.Lister()
+	cc.pcSynced = pcInformer.Informer().HasSynced
+
+	nodeInformer := cc.sharedInformers.Core().V1().Nodes()
+	cc.nodeLister = nodeInformer.Lister()
+	cc.nodeSynced = nodeInformer.Informer().HasSynced
+
 	cc.pgInformer = kbinfoext.NewSharedInformerFactory(cc.kbClients, 0).Scheduling().V1alpha1().PodGroups()
 	cc.pgInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		UpdateFunc: cc.updatePodGroup,
@@ -157,6 +176,7 @@ func NewJobController(config *rest.Config) *Controller {
 	// Register actions
 	state.SyncJob = cc.syncJob
 	state.KillJob = cc.killJob
+	state.KillTask = cc.killTask
 
 	return cc
 }
@@ -169,12 +189,13 @@ func (cc *Controller) Run(stopCh <-chan struct{}) {
 	go cc.sharedInformers.Start(stopCh)
 
 	cache.WaitForCacheSync(stopCh, cc.jobSynced, cc.podSynced, cc.pgSynced,
-		cc.svcSynced, cc.cmdSynced, cc.pvcSynced)
+		cc.svcSynced, cc.cmdSynced, cc.pvcSynced, cc.pcSynced, cc.nodeSynced)
 
 	go wait.Until(cc.handleCommands, 0, stopCh)
 	go wait.Until(cc.worker, 0, stopCh)
 
 	go cc.cache.Run(stopCh)
+	go wait.Until(cc.handleTTLJobs, 0, stopCh)
 
 	glog.Infof("JobController is running ...... ")
 }
@@ -210,11 +231,44 @@ func (cc *Controller) processNextReq() bool {
 		return true
 	}
 
-	action := applyPolicies(jobInfo.Job, &req)
+	if req.Delayed {
+		// The PodGroup is used to check whether the unschedulable Job was scheduled.
+		pg, err := cc.pgLister.PodGroups(req.Namespace).Get(req.JobName)
+		if err != nil {
+			pg = nil
+		}
+		if delayedActionCancelled(jobInfo, pg, &req) {
+			glog.V(3).Infof("Delayed action <%s> on Job <%s/%s> is cancelled.",
+				req.Action, req.Namespace, req.JobName)
+			removeDelayedAction(jobInfo.Job, &req)
+			cc.queue.Forget(req)
+			return true
+		}
+		// The action is taken now, the next status update will persist it.
+		removeDelayedAction(jobInfo.Job, &req)
+	}
+
+	action, timeout := applyPolicies(jobInfo, &req)
+	if timeout != nil && timeout.Duration > 0 {
+		cc.delayAction(jobInfo, req, action, timeout.Duration)
+		// Keep syncing Job until the delayed action is taken or cancelled.
+		action = vkv1.SyncJobAction
+	}
+
+	if action == vkv1.RestartTaskAction && len(req.TaskName) == 0 {
+		glog.Warningf("No task to restart in request <%v>, sync Job instead.", req)
+		action = vkv1.SyncJobAction
+	}
+
+	// Record why the policy is fired for the failed pod.
+	if podFailureEvents[req.Event] && action != vkv1.SyncJobAction {
+		cc.recordPodFailure(jobInfo, &req, action)
+	}
+
 	glog.V(3).Infof("Execute <%v> on Job <%s/%s> in <%s> by <%T>.",
 		action, req.Namespace, req.JobName, jobInfo.Job.Status.State.Phase, st)
 
-	if err := st.Execute(action); err != nil {
+	if err := st.Execute(action, req.TaskName); err != nil {
 		glog.Errorf("Failed to handle Job <%s/%s>: %v",
 			jobInfo.Job.Namespace, jobInfo.Job.Name, err)
 		// If any error, requeue it.
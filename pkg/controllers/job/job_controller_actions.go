@@ -17,23 +17,29 @@ limitations under the License.
 package job
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/golang/glog"
 
 	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
 
 	admissioncontroller "volcano.sh/volcano/pkg/admission"
 	vkbatchv1 "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
 	vkv1 "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
 	"volcano.sh/volcano/pkg/apis/helpers"
 	"volcano.sh/volcano/pkg/controllers/apis"
-	vkjobhelpers "volcano.sh/volcano/pkg/controllers/job/helpers"
 	"volcano.sh/volcano/pkg/controllers/job/state"
 )
 
@@ -42,8 +48,13 @@ func (cc *Controller) killJob(jobInfo *apis.JobInfo, nextState state.NextStateFn
 	defer glog.V(3).Infof("Finished Job <%s/%s> killing", jobInfo.Job.Namespace, jobInfo.Job.Name)
 
 	job := jobInfo.Job
-	// Job version is bumped only when job is killed
-	job.Status.Version = job.Status.Version + 1
+	// Job version is bumped only when job is killed; the following stages of
+	// an ordered teardown only kill the remaining pods of the killed version.
+	teardownStarted := !state.TeardownInProgress(jobInfo)
+	oldVersion := job.Status.Version
+	if teardownStarted {
+		job.Status.Version = job.Status.Version + 1
+	}
 	glog.Infof("Current Version is: %d of job: %s/%s", job.Status.Version, job.Namespace, job.Name)
 	if job.DeletionTimestamp != nil {
 		glog.Infof("Job <%s/%s> is terminating, skip management process.",
@@ -54,34 +65,59 @@ func (cc *Controller) killJob(jobInfo *apis.JobInfo, nextState state.NextStateFn
 	var pending, running, terminating, succeeded, failed int32
 
 	var errs []error
-	var total int
+	var total, kept int
+
+	taskStatuses := map[string]vkv1.TaskStatus{}
+
+	// Only the pods of the first teardown stage with remaining pods are killed,
+	// the others are kept until they are gone.
+	stage := currentTeardownStage(jobInfo)
+
+	for taskName, pods := range jobInfo.Pods {
+		var taskStatus vkv1.TaskStatus
 
-	for _, pods := range jobInfo.Pods {
 		for _, pod := range pods {
 			total++
 
 			if pod.DeletionTimestamp != nil {
 				glog.Infof("Pod <%s/%s> is terminating", pod.Namespace, pod.Name)
 				terminating++
+				taskStatus.Terminating++
 				continue
 			}
 
-			if err := cc.deleteJobPod(job.Name, pod); err == nil {
-				terminating++
-			} else {
-				errs = append(errs, err)
-				switch pod.Status.Phase {
-				case v1.PodRunning:
-					running++
-				case v1.PodPending:
-					pending++
-				case v1.PodSucceeded:
-					succeeded++
-				case v1.PodFailed:
-					failed++
+			if teardownStage(job, taskName) <= stage {
+				retained, err := cc.killJobPod(job, pod)
+				if err == nil {
+					// The retained pod is excluded from the counters of Job.
+					if !retained {
+						terminating++
+						taskStatus.Terminating++
+					}
+					continue
 				}
+				errs = append(errs, err)
+			} else {
+				kept++
+			}
+
+			switch pod.Status.Phase {
+			case v1.PodRunning:
+				running++
+				taskStatus.Running++
+			case v1.PodPending:
+				pending++
+				taskStatus.Pending++
+			case v1.PodSucceeded:
+				succeeded++
+				taskStatus.Succeeded++
+			case v1.PodFailed:
+				failed++
+				taskStatus.Failed++
 			}
 		}
+
+		taskStatuses[taskName] = taskStatus
 	}
 
 	if len(errs) != 0 {
@@ -89,20 +125,47 @@ func (cc *Controller) killJob(jobInfo *apis.JobInfo, nextState state.NextStateFn
 		return fmt.Errorf("failed to kill %d pods of %d", len(errs), total)
 	}
 
+	tolerated := countToleratedFailures(job, taskStatuses)
+
+	// The delayed actions are cleared explicitly once the version of Job is
+	// bumped, as they're cancelled when their timeout expires, see
+	// delayedActionCancelled.
+	delayedActions := job.Status.DelayedActions
+	if teardownStarted {
+		delayedActions = nil
+	}
+
 	job.Status = vkv1.JobStatus{
 		State: job.Status.State,
 
-		Pending:      pending,
-		Running:      running,
-		Succeeded:    succeeded,
-		Failed:       failed,
-		Terminating:  terminating,
-		Version:      job.Status.Version,
-		MinAvailable: int32(job.Spec.MinAvailable),
+		Pending:            pending,
+		Running:            running,
+		Succeeded:          succeeded,
+		Failed:             failed,
+		Terminating:        terminating,
+		Version:            job.Status.Version,
+		MinAvailable:       int32(job.Spec.MinAvailable),
+		DelayedActions:     delayedActions,
+		RetryCount:         job.Status.RetryCount,
+		NextRestartTime:    job.Status.NextRestartTime,
+		StartTime:          job.Status.StartTime,
+		FinishTime:         job.Status.FinishTime,
+		Conditions:         job.Status.Conditions,
+		TaskStatuses:       taskStatuses,
+		TaskVersions:       job.Status.TaskVersions,
+		Tolerated:          tolerated,
+		LastFailures:       job.Status.LastFailures,
+		ObservedGeneration: job.Status.ObservedGeneration,
 	}
 
-	if nextState != nil {
-		job.Status.State = nextState(job.Status)
+	updateJobState(job, nextState)
+
+	if job.Status.State.Phase == vkv1.Restarting && teardownStarted {
+		if err := cc.pluginOnJobRestart(job, oldVersion, job.Status.Version); err != nil {
+			cc.recorder.Event(job, v1.EventTypeWarning, string(vkbatchv1.PluginError),
+				fmt.Sprintf("Plugin failed when been executed at job restart, err: %v", err))
+			return err
+		}
 	}
 
 	// Update Job status
@@ -115,6 +178,26 @@ func (cc *Controller) killJob(jobInfo *apis.JobInfo, nextState state.NextStateFn
 			return e
 		}
 	}
+	cc.enqueueTTLJob(job)
+
+	// Sync Job again once the restart backoff expires, the pods are held back
+	// in Restarting phase until then.
+	if job.Status.State.Phase == vkv1.Restarting && job.Status.NextRestartTime != nil {
+		cc.queue.AddAfter(apis.Request{
+			Namespace: job.Namespace,
+			JobName:   job.Name,
+
+			Event: vkv1.OutOfSyncEvent,
+		}, time.Until(job.Status.NextRestartTime.Time))
+	}
+
+	// The pods of the later teardown stages are still running, e.g. the chief is saving
+	// checkpoints, keep the resources of Job until the last stage is killed.
+	if kept != 0 {
+		glog.V(3).Infof("Keep resources of Job <%s/%s> for %d pods of the later teardown stages",
+			job.Namespace, job.Name, kept)
+		return nil
+	}
 
 	// Delete PodGroup
 	if err := cc.kbClients.SchedulingV1alpha1().PodGroups(job.Namespace).Delete(job.Name, nil); err != nil {
@@ -145,6 +228,124 @@ func (cc *Controller) killJob(jobInfo *apis.JobInfo, nextState state.NextStateFn
 	return nil
 }
 
+// killTask kills the pods of the task without bumping the version of Job; the
+// task version is bumped instead, and the pods are re-created by SyncJob.
+func (cc *Controller) killTask(jobInfo *apis.JobInfo, taskName string, nextState state.NextStateFn) error {
+	glog.V(3).Infof("Killing Task <%s> of Job <%s/%s>", taskName, jobInfo.Job.Namespace, jobInfo.Job.Name)
+	defer glog.V(3).Infof("Finished Task <%s> of Job <%s/%s> killing", taskName, jobInfo.Job.Namespace, jobInfo.Job.Name)
+
+	job := jobInfo.Job
+	if job.DeletionTimestamp != nil {
+		glog.Infof("Job <%s/%s> is terminating, skip management process.",
+			job.Namespace, job.Name)
+		return nil
+	}
+
+	taskVersions := map[string]int32{}
+	for name, version := range job.Status.TaskVersions {
+		taskVersions[name] = version
+	}
+	taskVersions[taskName]++
+	glog.Infof("Current Version is: %d of task: %s of job: %s/%s",
+		taskVersions[taskName], taskName, job.Namespace, job.Name)
+
+	var pending, running, terminating, succeeded, failed int32
+
+	var errs []error
+	var total int
+
+	taskStatuses := map[string]vkv1.TaskStatus{}
+
+	for name, pods := range jobInfo.Pods {
+		var taskStatus vkv1.TaskStatus
+
+		for _, pod := range pods {
+			if pod.DeletionTimestamp != nil {
+				glog.Infof("Pod <%s/%s> is terminating", pod.Namespace, pod.Name)
+				terminating++
+				taskStatus.Terminating++
+				continue
+			}
+
+			if name == taskName {
+				total++
+				retained, err := cc.killJobPod(job, pod)
+				if err == nil {
+					// The retained pod is excluded from the counters of Job.
+					if !retained {
+						terminating++
+						taskStatus.Terminating++
+					}
+					continue
+				}
+				errs = append(errs, err)
+			}
+
+			switch pod.Status.Phase {
+			case v1.PodRunning:
+				running++
+				taskStatus.Running++
+			case v1.PodPending:
+				pending++
+				taskStatus.Pending++
+			case v1.PodSucceeded:
+				succeeded++
+				taskStatus.Succeeded++
+			case v1.PodFailed:
+				failed++
+				taskStatus.Failed++
+			}
+		}
+
+		taskStatuses[name] = taskStatus
+	}
+
+	if len(errs) != 0 {
+		glog.Errorf("failed to kill pods of task %s for job %s/%s, with err %+v", taskName, job.Namespace, job.Name, errs)
+		return fmt.Errorf("failed to kill %d pods of %d", len(errs), total)
+	}
+
+	tolerated := countToleratedFailures(job, taskStatuses)
+
+	job.Status = vkv1.JobStatus{
+		State: job.Status.State,
+
+		Pending:             pending,
+		Running:             running,
+		Succeeded:           succeeded,
+		Failed:              failed,
+		Terminating:         terminating,
+		Version:             job.Status.Version,
+		MinAvailable:        int32(job.Spec.MinAvailable),
+		ControlledResources: job.Status.ControlledResources,
+		DelayedActions:      job.Status.DelayedActions,
+		RetryCount:          job.Status.RetryCount,
+		NextRestartTime:     job.Status.NextRestartTime,
+		StartTime:           job.Status.StartTime,
+		FinishTime:          job.Status.FinishTime,
+		Conditions:          job.Status.Conditions,
+		TaskStatuses:        taskStatuses,
+		TaskVersions:        taskVersions,
+		Tolerated:           tolerated,
+		LastFailures:        job.Status.LastFailures,
+		ObservedGeneration:  job.Status.ObservedGeneration,
+	}
+
+	updateJobState(job, nextState)
+
+	if job, err := cc.vkClients.BatchV1alpha1().Jobs(job.Namespace).UpdateStatus(job); err != nil {
+		glog.Errorf("Failed to update status of Job %v/%v: %v",
+			job.Namespace, job.Name, err)
+		return err
+	} else {
+		if e := cc.cache.Update(job); e != nil {
+			return e
+		}
+	}
+
+	return nil
+}
+
 func (cc *Controller) syncJob(jobInfo *apis.JobInfo, nextState state.NextStateFn) error {
 	glog.V(3).Infof("Starting to sync up Job <%s/%s>", jobInfo.Job.Namespace, jobInfo.Job.Name)
 	defer glog.V(3).Infof("Finished Job <%s/%s> sync up", jobInfo.Job.Namespace, jobInfo.Job.Name)
@@ -158,7 +359,7 @@ func (cc *Controller) syncJob(jobInfo *apis.JobInfo, nextState state.NextStateFn
 		return nil
 	}
 
-	if err := cc.createPodGroupIfNotExist(job); err != nil {
+	if err := cc.createOrUpdatePodGroup(job); err != nil {
 		return err
 	}
 
@@ -176,6 +377,12 @@ func (cc *Controller) syncJob(jobInfo *apis.JobInfo, nextState state.NextStateFn
 		return err
 	}
 
+	if err := cc.pluginOnJobUpdate(job); err != nil {
+		cc.recorder.Event(job, v1.EventTypeWarning, string(vkbatchv1.PluginError),
+			fmt.Sprintf("Plugin failed when been executed at job update, err: %v", err))
+		return err
+	}
+
 	var running, pending, terminating, succeeded, failed int32
 
 	var podToCreate []*v1.Pod
@@ -183,6 +390,8 @@ func (cc *Controller) syncJob(jobInfo *apis.JobInfo, nextState state.NextStateFn
 	var creationErrs []error
 	var deletionErrs []error
 
+	taskStatuses := map[string]vkv1.TaskStatus{}
+
 	for _, ts := range job.Spec.Tasks {
 		ts.Template.Name = ts.Name
 		tc := ts.Template.DeepCopy()
@@ -193,38 +402,60 @@ func (cc *Controller) syncJob(jobInfo *apis.JobInfo, nextState state.NextStateFn
 			pods = map[string]*v1.Pod{}
 		}
 
+		// Hold the pods of task back until its dependencies are satisfied.
+		dependsOnSatisfied := dependenciesSatisfied(jobInfo, &ts)
+
+		// The pods to create or delete are counted as pending or terminating,
+		// the status will not be updated if any of them failed.
+		var taskStatus vkv1.TaskStatus
+
 		for i := 0; i < int(ts.Replicas); i++ {
-			podName := fmt.Sprintf(vkjobhelpers.TaskNameFmt, job.Name, name, i)
+			podName := jobPodName(job, name, i)
 			if pod, found := pods[podName]; !found {
+				if !dependsOnSatisfied {
+					continue
+				}
 				newPod := createJobPod(job, tc, i)
 				if err := cc.pluginOnPodCreate(job, newPod); err != nil {
 					return err
 				}
 				podToCreate = append(podToCreate, newPod)
+				taskStatus.Pending++
 			} else {
 				delete(pods, podName)
 				if pod.DeletionTimestamp != nil {
 					glog.Infof("Pod <%s/%s> is terminating", pod.Namespace, pod.Name)
 					terminating++
+					taskStatus.Terminating++
 					continue
 				}
 
 				switch pod.Status.Phase {
 				case v1.PodPending:
 					pending++
+					taskStatus.Pending++
 				case v1.PodRunning:
 					running++
+					taskStatus.Running++
 				case v1.PodSucceeded:
 					succeeded++
+					taskStatus.Succeeded++
 				case v1.PodFailed:
 					failed++
+					taskStatus.Failed++
 				}
 			}
 		}
 
 		for _, pod := range pods {
+			if err := cc.pluginOnPodDelete(job, pod); err != nil {
+				return err
+			}
 			podToDelete = append(podToDelete, pod)
+			taskStatus.Terminating++
 		}
+
+		taskStatuses[name] = taskStatus
 	}
 
 	waitCreationGroup := sync.WaitGroup{}
@@ -260,7 +491,7 @@ func (cc *Controller) syncJob(jobInfo *apis.JobInfo, nextState state.NextStateFn
 	for _, pod := range podToDelete {
 		go func(pod *v1.Pod) {
 			defer waitDeletionGroup.Done()
-			err := cc.deleteJobPod(job.Name, pod)
+			err := cc.deleteScaledDownPod(job, pod)
 			if err != nil {
 				// Failed to delete Pod, waitCreationGroup a moment and then create it again
 				// This is to ensure all podsMap under the same Job created
@@ -281,6 +512,8 @@ func (cc *Controller) syncJob(jobInfo *apis.JobInfo, nextState state.NextStateFn
 		return fmt.Errorf("failed to delete %d pods of %d", len(deletionErrs), len(podToDelete))
 	}
 
+	tolerated := countToleratedFailures(job, taskStatuses)
+
 	job.Status = vkv1.JobStatus{
 		State: job.Status.State,
 
@@ -292,12 +525,26 @@ func (cc *Controller) syncJob(jobInfo *apis.JobInfo, nextState state.NextStateFn
 		Version:             job.Status.Version,
 		MinAvailable:        int32(job.Spec.MinAvailable),
 		ControlledResources: job.Status.ControlledResources,
+		DelayedActions:      pendingDelayedActions(jobInfo),
+		RetryCount:          job.Status.RetryCount,
+		NextRestartTime:     job.Status.NextRestartTime,
+		StartTime:           job.Status.StartTime,
+		FinishTime:          job.Status.FinishTime,
+		Conditions:          job.Status.Conditions,
+		TaskStatuses:        taskStatuses,
+		TaskVersions:        job.Status.TaskVersions,
+		Tolerated:           tolerated,
+		LastFailures:        job.Status.LastFailures,
+		ObservedGeneration:  job.Status.ObservedGeneration,
 	}
 
-	if nextState != nil {
-		job.Status.State = nextState(job.Status)
+	if job.Status.StartTime == nil {
+		now := metav1.Now()
+		job.Status.StartTime = &now
 	}
 
+	updateJobState(job, nextState)
+
 	if job, err := cc.vkClients.BatchV1alpha1().Jobs(job.Namespace).UpdateStatus(job); err != nil {
 		glog.Errorf("Failed to update status of Job %v/%v: %v",
 			job.Namespace, job.Name, err)
@@ -307,10 +554,88 @@ func (cc *Controller) syncJob(jobInfo *apis.JobInfo, nextState state.NextStateFn
 			return e
 		}
 	}
+	cc.enqueueTTLJob(job)
+
+	// Sync Job again once its deadline is exceeded, it'll be terminated then.
+	if remaining, found := state.DeadlineRemaining(job); found &&
+		(job.Status.State.Phase == vkv1.Pending || job.Status.State.Phase == vkv1.Running) {
+		cc.queue.AddAfter(apis.Request{
+			Namespace: job.Namespace,
+			JobName:   job.Name,
+
+			Event: vkv1.OutOfSyncEvent,
+		}, remaining)
+	}
 
 	return nil
 }
 
+// delayAction records the action in job status and re-queues the request
+// after the timeout of the matched LifecyclePolicy; the status is persisted
+// by the following sync.
+func (cc *Controller) delayAction(jobInfo *apis.JobInfo, req apis.Request, action vkv1.Action, delay time.Duration) {
+	job := jobInfo.Job
+	req.Action = action
+	req.Delayed = true
+
+	// Re-arm the scheduled action, the request may be lost, e.g. the controller was restarted.
+	if idx := findDelayedAction(job, &req, action); idx >= 0 {
+		glog.V(3).Infof("Action <%s> on Job <%s/%s> by <%s> is already scheduled.",
+			action, job.Namespace, job.Name, req.Event)
+		cc.queue.AddAfter(req, time.Until(job.Status.DelayedActions[idx].ScheduledTime.Time))
+		return
+	}
+
+	job.Status.DelayedActions = append(job.Status.DelayedActions, vkv1.DelayedAction{
+		Action:        action,
+		Event:         req.Event,
+		TaskName:      req.TaskName,
+		PodName:       req.PodName,
+		ScheduledTime: metav1.NewTime(time.Now().Add(delay)),
+	})
+
+	glog.Infof("Action <%s> on Job <%s/%s> by <%s> is scheduled in %v.",
+		action, job.Namespace, job.Name, req.Event, delay)
+
+	cc.queue.AddAfter(req, delay)
+}
+
+// enqueueDelayedActions re-queues the delayed actions recorded in job status, e.g. when
+// the controller is restarted; they're taken at their scheduled time.
+func (cc *Controller) enqueueDelayedActions(job *vkv1.Job) {
+	for _, da := range job.Status.DelayedActions {
+		req := apis.Request{
+			Namespace: job.Namespace,
+			JobName:   job.Name,
+			TaskName:  da.TaskName,
+			PodName:   da.PodName,
+
+			Event:       da.Event,
+			Action:      da.Action,
+			JobVersion:  job.Status.Version,
+			TaskVersion: job.Status.TaskVersions[da.TaskName],
+			Delayed:     true,
+		}
+		cc.queue.AddAfter(req, time.Until(da.ScheduledTime.Time))
+	}
+}
+
+// enqueueTTLJob adds the finished Job to ttlQueue, it'll be deleted once its TTL expires.
+func (cc *Controller) enqueueTTLJob(job *vkv1.Job) {
+	if job.Spec.TTLSecondsAfterFinished == nil || job.Status.FinishTime == nil {
+		return
+	}
+
+	key, err := cache.MetaNamespaceKeyFunc(job)
+	if err != nil {
+		glog.Errorf("Failed to get key of Job <%s/%s>: %v", job.Namespace, job.Name, err)
+		return
+	}
+
+	_, remaining := ttlExpired(job)
+	cc.ttlQueue.AddAfter(key, remaining)
+}
+
 func (cc *Controller) createServiceIfNotExist(job *vkv1.Job) error {
 	// If Service does not exist, create one for Job.
 	if _, err := cc.svcLister.Services(job.Namespace).Get(job.Name); err != nil {
@@ -336,7 +661,7 @@ func (cc *Controller) createServiceIfNotExist(job *vkv1.Job) error {
 				},
 				Ports: []v1.ServicePort{
 					{
-						Name:       "placeholder-volcano",
+						Name:       helpers.ServicePlaceholderPort,
 						Port:       1,
 						Protocol:   v1.ProtocolTCP,
 						TargetPort: intstr.FromInt(1),
@@ -424,15 +749,16 @@ func (cc *Controller) createJobIOIfNotExist(job *vkv1.Job) error {
 	return nil
 }
 
-func (cc *Controller) createPodGroupIfNotExist(job *vkv1.Job) error {
+func (cc *Controller) createOrUpdatePodGroup(job *vkv1.Job) error {
 	// If PodGroup does not exist, create one for Job.
-	if _, err := cc.pgLister.PodGroups(job.Namespace).Get(job.Name); err != nil {
+	pg, err := cc.pgLister.PodGroups(job.Namespace).Get(job.Name)
+	if err != nil {
 		if !apierrors.IsNotFound(err) {
 			glog.V(3).Infof("Failed to get PodGroup for Job <%s/%s>: %v",
 				job.Namespace, job.Name, err)
 			return err
 		}
-		pg := &kbv1.PodGroup{
+		pg = &kbv1.PodGroup{
 			ObjectMeta: metav1.ObjectMeta{
 				Namespace: job.Namespace,
 				Name:      job.Name,
@@ -441,8 +767,10 @@ func (cc *Controller) createPodGroupIfNotExist(job *vkv1.Job) error {
 				},
 			},
 			Spec: kbv1.PodGroupSpec{
-				MinMember: job.Spec.MinAvailable,
-				Queue:     job.Spec.Queue,
+				MinMember:         job.Spec.MinAvailable,
+				Queue:             job.Spec.Queue,
+				PriorityClassName: job.Spec.PriorityClassName,
+				MinResources:      cc.calcPGMinResources(job),
 			},
 		}
 
@@ -454,16 +782,313 @@ func (cc *Controller) createPodGroupIfNotExist(job *vkv1.Job) error {
 				return err
 			}
 		}
+
+		return nil
+	}
+
+	// Keep MinMember and MinResources of PodGroup up to date once the Job is scaled.
+	minResources := cc.calcPGMinResources(job)
+	if pg.Spec.MinMember != job.Spec.MinAvailable ||
+		!equality.Semantic.DeepEqual(pg.Spec.MinResources, minResources) {
+		pg = pg.DeepCopy()
+		pg.Spec.MinMember = job.Spec.MinAvailable
+		pg.Spec.MinResources = minResources
+		if _, err := cc.kbClients.SchedulingV1alpha1().PodGroups(job.Namespace).Update(pg); err != nil {
+			glog.V(3).Infof("Failed to update PodGroup for Job <%s/%s>: %v",
+				job.Namespace, job.Name, err)
+			return err
+		}
 	}
 
 	return nil
 }
 
-func (cc *Controller) deleteJobPod(jobName string, pod *v1.Pod) error {
-	err := cc.kubeClients.CoreV1().Pods(pod.Namespace).Delete(pod.Name, nil)
+// calcPGMinResources returns the total resource requests of the first
+// 'minAvailable' pods of Job, the pods of higher priority tasks go first.
+func (cc *Controller) calcPGMinResources(job *vkv1.Job) *v1.ResourceList {
+	tasks := make([]vkv1.TaskSpec, len(job.Spec.Tasks))
+	copy(tasks, job.Spec.Tasks)
+
+	priorities := map[string]int32{}
+	for _, task := range tasks {
+		priorities[task.Name] = cc.taskPriority(&task)
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return priorities[tasks[i].Name] > priorities[tasks[j].Name]
+	})
+
+	minResources := v1.ResourceList{}
+	podCount := int32(0)
+	for _, task := range tasks {
+		for i := int32(0); i < task.Replicas && podCount < job.Spec.MinAvailable; i++ {
+			addResourceList(minResources, podRequests(&task.Template.Spec))
+			podCount++
+		}
+	}
+
+	return &minResources
+}
+
+// taskPriority returns the priority of pods of the task, it's 0 if the
+// priority class is not found.
+func (cc *Controller) taskPriority(task *vkv1.TaskSpec) int32 {
+	if task.Template.Spec.Priority != nil {
+		return *task.Template.Spec.Priority
+	}
+
+	if len(task.Template.Spec.PriorityClassName) == 0 {
+		return 0
+	}
+
+	pc, err := cc.pcLister.Get(task.Template.Spec.PriorityClassName)
+	if err != nil {
+		glog.V(3).Infof("Failed to get PriorityClass <%s> of task <%s>: %v",
+			task.Template.Spec.PriorityClassName, task.Name, err)
+		return 0
+	}
+
+	return pc.Value
+}
+
+// recordPodFailure records the failure of pod which fires the action into
+// 'lastFailures' of Job, the next status update will persist it; and emits
+// an event for it.
+func (cc *Controller) recordPodFailure(jobInfo *apis.JobInfo, req *apis.Request, action vkv1.Action) {
+	pod, found := jobInfo.Pods[req.TaskName][req.PodName]
+	if !found {
+		glog.V(3).Infof("Failed to find failed Pod <%s/%s> of Job <%s/%s> in cache",
+			req.Namespace, req.PodName, req.Namespace, req.JobName)
+		return
+	}
+
+	if podFailureRecorded(jobInfo.Job, pod) {
+		glog.V(3).Infof("Failure of Pod <%s/%s> is already recorded in Job <%s/%s>",
+			pod.Namespace, pod.Name, req.Namespace, req.JobName)
+		return
+	}
+
+	failure := newPodFailure(pod, req, action)
+	appendPodFailure(jobInfo.Job, failure)
+	if len(failure.Message) == 0 && len(failure.ContainerName) != 0 {
+		// Get the log out of the worker of Job, it may take a while.
+		go cc.recordContainerLogTail(jobInfo.Job, pod, failure.ContainerName)
+	}
+
+	msg := fmt.Sprintf("Pod <%s> of task <%s> failed by <%s>, action <%s> is taken",
+		failure.PodName, failure.TaskName, failure.Event, failure.Action)
+	if len(failure.ContainerName) != 0 {
+		msg = msg + fmt.Sprintf(": container <%s> exited with code %d", failure.ContainerName, failure.ExitCode)
+	}
+	if len(failure.Reason) != 0 {
+		msg = msg + fmt.Sprintf(", reason: %s", failure.Reason)
+	}
+	cc.recorder.Event(jobInfo.Job, v1.EventTypeWarning, string(vkv1.PodFailed), msg)
+}
+
+// recordContainerLogTail sets the last lines of the log of container as the message of
+// the recorded failure of pod, once the failure is persisted by the worker of Job.
+func (cc *Controller) recordContainerLogTail(job *vkv1.Job, pod *v1.Pod, container string) {
+	logs := cc.containerLogTail(pod, container)
+	if len(logs) == 0 {
+		return
+	}
+
+	err := wait.PollImmediate(time.Second, failureLogTimeout, func() (bool, error) {
+		latest, err := cc.vkClients.BatchV1alpha1().Jobs(job.Namespace).Get(job.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+
+		idx := -1
+		for i, failure := range latest.Status.LastFailures {
+			if failure.PodUID == pod.UID {
+				idx = i
+				break
+			}
+		}
+		// The failure is not persisted yet, or dropped by the newer ones.
+		if idx < 0 {
+			return false, nil
+		}
+		if len(latest.Status.LastFailures[idx].Message) != 0 {
+			return true, nil
+		}
+
+		latest.Status.LastFailures[idx].Message = logs
+		updated, err := cc.vkClients.BatchV1alpha1().Jobs(job.Namespace).UpdateStatus(latest)
+		if err != nil {
+			if apierrors.IsConflict(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		if err := cc.cache.Update(updated); err != nil {
+			glog.Errorf("Failed to update Job <%s/%s> in cache: %v", job.Namespace, job.Name, err)
+		}
+
+		return true, nil
+	})
+	if err != nil {
+		glog.V(3).Infof("Failed to record log of container %s of Pod <%s/%s> into Job <%s/%s>: %v",
+			container, pod.Namespace, pod.Name, job.Namespace, job.Name, err)
+	}
+}
+
+// containerLogTail returns the last lines of the log of container, empty if
+// failed to get it. It's skipped if the node is lost and limited by 'failureLogTimeout'.
+func (cc *Controller) containerLogTail(pod *v1.Pod, container string) string {
+	if cc.podNodeLost(pod) {
+		glog.V(3).Infof("Skip getting log of container %s of Pod <%s/%s>, its node %s is lost",
+			container, pod.Namespace, pod.Name, pod.Spec.NodeName)
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), failureLogTimeout)
+	defer cancel()
+
+	tailLines := int64(maxFailureMessageLines)
+	logs, err := cc.kubeClients.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &v1.PodLogOptions{
+		Container: container,
+		TailLines: &tailLines,
+	}).Context(ctx).DoRaw()
+	if err != nil {
+		glog.V(3).Infof("Failed to get log of container %s of Pod <%s/%s>: %v",
+			container, pod.Namespace, pod.Name, err)
+		return ""
+	}
+
+	return lastLines(string(logs), maxFailureMessageLines)
+}
+
+// killJobPod deletes the pod of Job, or retains it if it's failed and the
+// retention policy of Job is set; it returns whether the pod is retained.
+func (cc *Controller) killJobPod(job *vkv1.Job, pod *v1.Pod) (bool, error) {
+	if err := cc.pluginOnPodDelete(job, pod); err != nil {
+		cc.recorder.Event(job, v1.EventTypeWarning, string(vkbatchv1.PluginError),
+			fmt.Sprintf("Plugin failed when been executed at pod delete, err: %v", err))
+		return false, err
+	}
+
+	if policy := job.Spec.RetentionPolicy; policy == nil || policy.MaxFailedPods <= 0 ||
+		pod.Status.Phase != v1.PodFailed {
+		return false, cc.deleteJobPod(job, pod)
+	}
+
+	if err := cc.retainJobPod(job, pod); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// retainJobPod labels the failed pod as retained and releases it from Job, so
+// it's ignored by controller but still garbage collected with Job. The oldest
+// retained pods of the task are deleted if 'maxFailedPods' is exceeded.
+func (cc *Controller) retainJobPod(job *vkv1.Job, pod *v1.Pod) error {
+	retained := pod.DeepCopy()
+	if retained.Labels == nil {
+		retained.Labels = map[string]string{}
+	}
+	retained.Labels[vkv1.RetainedPodKey] = "true"
+	for i := range retained.OwnerReferences {
+		retained.OwnerReferences[i].Controller = nil
+	}
+
+	retained, err := cc.kubeClients.CoreV1().Pods(pod.Namespace).Update(retained)
+	if err != nil {
+		glog.Errorf("Failed to retain pod %s/%s for Job %s, err %#v",
+			pod.Namespace, pod.Name, job.Name, err)
+		return err
+	}
+
+	if err := cc.cache.DeletePod(pod); err != nil {
+		glog.Errorf("Failed to delete retained Pod <%s/%s> from cache: %v",
+			pod.Namespace, pod.Name, err)
+	}
+
+	glog.V(3).Infof("Retained failed Pod <%s/%s> of Job <%s/%s>",
+		pod.Namespace, pod.Name, job.Namespace, job.Name)
+
+	return cc.cleanupRetainedPods(job, retained)
+}
+
+// cleanupRetainedPods deletes the oldest retained pods of the task of the
+// newly retained pod, if 'maxFailedPods' is exceeded.
+func (cc *Controller) cleanupRetainedPods(job *vkv1.Job, newlyRetained *v1.Pod) error {
+	selector := labels.SelectorFromSet(labels.Set{vkv1.RetainedPodKey: "true"})
+	pods, err := cc.podLister.Pods(job.Namespace).List(selector)
+	if err != nil {
+		return err
+	}
+
+	taskName := newlyRetained.Annotations[vkv1.TaskSpecKey]
+	retainedPods := []*v1.Pod{newlyRetained}
+	for _, pod := range pods {
+		// The lister may not have seen the newly retained pod yet.
+		if pod.Name == newlyRetained.Name || pod.DeletionTimestamp != nil ||
+			pod.Annotations[vkv1.TaskSpecKey] != taskName || !ownedBy(pod, job) {
+			continue
+		}
+		retainedPods = append(retainedPods, pod)
+	}
+
+	max := int(job.Spec.RetentionPolicy.MaxFailedPods)
+	if len(retainedPods) <= max {
+		return nil
+	}
+
+	// Newest first
+	sort.Slice(retainedPods, func(i, j int) bool {
+		return retainedPods[j].CreationTimestamp.Before(&retainedPods[i].CreationTimestamp)
+	})
+
+	var errs []error
+	for _, pod := range retainedPods[max:] {
+		if err := cc.deleteJobPod(job, pod); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) != 0 {
+		return fmt.Errorf("failed to delete %d retained pods of %d", len(errs), len(retainedPods)-max)
+	}
+
+	return nil
+}
+
+// deleteScaledDownPod annotates the pod which is not desired by Job any more, e.g.
+// scaled down, before deleting it; so its deletion is not handled as an eviction.
+func (cc *Controller) deleteScaledDownPod(job *vkv1.Job, pod *v1.Pod) error {
+	if !podScaledDown(pod) {
+		scaledDown := pod.DeepCopy()
+		if scaledDown.Annotations == nil {
+			scaledDown.Annotations = map[string]string{}
+		}
+		scaledDown.Annotations[vkv1.ScaledDownPodKey] = "true"
+
+		if _, err := cc.kubeClients.CoreV1().Pods(pod.Namespace).Update(scaledDown); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			glog.Errorf("Failed to annotate scaled down pod %s/%s for Job %s, err %#v",
+				pod.Namespace, pod.Name, job.Name, err)
+			return err
+		}
+	}
+
+	return cc.deleteJobPod(job, pod)
+}
+
+func (cc *Controller) deleteJobPod(job *vkv1.Job, pod *v1.Pod) error {
+	var options *metav1.DeleteOptions
+	if policy := job.Spec.TerminationPolicy; policy != nil && policy.GracePeriodSeconds != nil {
+		options = &metav1.DeleteOptions{GracePeriodSeconds: policy.GracePeriodSeconds}
+	}
+
+	err := cc.kubeClients.CoreV1().Pods(pod.Namespace).Delete(pod.Name, options)
 	if err != nil && !apierrors.IsNotFound(err) {
 		glog.Errorf("Failed to delete pod %s/%s for Job %s, err %#v",
-			pod.Namespace, pod.Name, jobName, err)
+			pod.Namespace, pod.Name, job.Name, err)
 
 		return err
 	}
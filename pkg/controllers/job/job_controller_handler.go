@@ -25,6 +25,7 @@ import (
 
 	"k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/cache"
 
 	kbtype "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
@@ -64,6 +65,8 @@ func (cc *Controller) addJob(obj interface{}) {
 			job.Namespace, job.Name, err)
 	}
 	cc.queue.Add(req)
+	cc.enqueueTTLJob(job)
+	cc.enqueueDelayedActions(job)
 }
 
 func (cc *Controller) updateJob(oldObj, newObj interface{}) {
@@ -130,6 +133,11 @@ func (cc *Controller) addPod(obj interface{}) {
 		return
 	}
 
+	// The retained pods are not managed by Job any more
+	if podRetained(pod) {
+		return
+	}
+
 	jobName, found := pod.Annotations[vkbatchv1.JobNameKey]
 	if !found {
 		glog.Infof("Failed to find jobName of Pod <%s/%s>, skipping",
@@ -179,6 +187,16 @@ func (cc *Controller) updatePod(oldObj, newObj interface{}) {
 		return
 	}
 
+	if podRetained(newPod) {
+		if !podRetained(oldPod) {
+			if err := cc.cache.DeletePod(newPod); err != nil {
+				glog.Errorf("Failed to delete retained Pod <%s/%s> from cache: %v",
+					newPod.Namespace, newPod.Name, err)
+			}
+		}
+		return
+	}
+
 	taskName, found := newPod.Annotations[vkbatchv1.TaskSpecKey]
 	if !found {
 		glog.Infof("Failed to find taskName of Pod <%s/%s>, skipping",
@@ -213,13 +231,13 @@ func (cc *Controller) updatePod(oldObj, newObj interface{}) {
 	}
 
 	event := vkbatchv1.OutOfSyncEvent
+	var baseEvent vkbatchv1.Event
 	var exitCode int32
 	if oldPod.Status.Phase != v1.PodFailed &&
-		newPod.Status.Phase == v1.PodFailed {
-		event = vkbatchv1.PodFailedEvent
-		// TODO: currently only one container pod is supported by volcano
-		// Once multi containers pod is supported, update accordingly.
-		exitCode = newPod.Status.ContainerStatuses[0].State.Terminated.ExitCode
+		newPod.Status.Phase == v1.PodFailed && !podScaledDown(newPod) {
+		event = podFailedEvent(newPod)
+		baseEvent = vkbatchv1.PodFailedEvent
+		exitCode = podExitCode(newPod)
 	}
 
 	if oldPod.Status.Phase != v1.PodSucceeded &&
@@ -233,10 +251,13 @@ func (cc *Controller) updatePod(oldObj, newObj interface{}) {
 		Namespace: newPod.Namespace,
 		JobName:   jobName,
 		TaskName:  taskName,
+		PodName:   newPod.Name,
 
-		Event:      event,
-		ExitCode:   exitCode,
-		JobVersion: int32(dVersion),
+		Event:       event,
+		BaseEvent:   baseEvent,
+		ExitCode:    exitCode,
+		JobVersion:  int32(dVersion),
+		TaskVersion: podTaskVersion(newPod),
 	}
 
 	cc.queue.Add(req)
@@ -258,6 +279,10 @@ func (cc *Controller) deletePod(obj interface{}) {
 		}
 	}
 
+	if podRetained(pod) {
+		return
+	}
+
 	taskName, found := pod.Annotations[vkbatchv1.TaskSpecKey]
 	if !found {
 		glog.Infof("Failed to find taskName of Pod <%s/%s>, skipping",
@@ -290,9 +315,18 @@ func (cc *Controller) deletePod(obj interface{}) {
 		Namespace: pod.Namespace,
 		JobName:   jobName,
 		TaskName:  taskName,
+		PodName:   pod.Name,
 
-		Event:      vkbatchv1.PodEvictedEvent,
-		JobVersion: int32(dVersion),
+		Event:       cc.podDeletedEvent(pod),
+		BaseEvent:   vkbatchv1.PodEvictedEvent,
+		JobVersion:  int32(dVersion),
+		TaskVersion: podTaskVersion(pod),
+	}
+
+	// The pods deleted by controller on scale-down are not evicted.
+	if podScaledDown(pod) {
+		req.Event = vkbatchv1.OutOfSyncEvent
+		req.BaseEvent = ""
 	}
 
 	if err := cc.cache.DeletePod(pod); err != nil {
@@ -303,6 +337,45 @@ func (cc *Controller) deletePod(obj interface{}) {
 	cc.queue.Add(req)
 }
 
+// podDeletedEvent classifies the deletion of pod by the markers of preemption
+// and the condition of its node; the policies of PodEvicted still apply to the
+// finer-grained events, see matchPolicy.
+func (cc *Controller) podDeletedEvent(pod *v1.Pod) vkbatchv1.Event {
+	switch {
+	case podPreempted(pod):
+		return vkbatchv1.PodPreemptedEvent
+	case cc.podNodeLost(pod):
+		return vkbatchv1.NodeLostEvent
+	}
+
+	return vkbatchv1.PodEvictedEvent
+}
+
+// podNodeLost returns whether the node of pod was lost, i.e. it was deleted or
+// its Ready condition is Unknown as node controller can't hear from it.
+func (cc *Controller) podNodeLost(pod *v1.Pod) bool {
+	if pod.Status.Reason == podReasonNodeLost {
+		return true
+	}
+
+	if len(pod.Spec.NodeName) == 0 {
+		return false
+	}
+
+	node, err := cc.nodeLister.Get(pod.Spec.NodeName)
+	if err != nil {
+		return apierrors.IsNotFound(err)
+	}
+
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == v1.NodeReady {
+			return cond.Status == v1.ConditionUnknown
+		}
+	}
+
+	return false
+}
+
 func (cc *Controller) recordJobEvent(namespace, name string, event vkbatchv1.JobEvent, message string) {
 	job, err := cc.cache.Get(vkcache.JobKeyByName(namespace, name))
 	if err != nil {
@@ -350,6 +423,62 @@ func (cc *Controller) processNextCommand() bool {
 	return true
 }
 
+func (cc *Controller) handleTTLJobs() {
+	for cc.processNextTTLJob() {
+	}
+}
+
+func (cc *Controller) processNextTTLJob() bool {
+	obj, shutdown := cc.ttlQueue.Get()
+	if shutdown {
+		return false
+	}
+	key := obj.(string)
+	defer cc.ttlQueue.Done(key)
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		glog.Errorf("Invalid key <%s> of finished job: %v", key, err)
+		cc.ttlQueue.Forget(key)
+		return true
+	}
+
+	job, err := cc.jobLister.Jobs(namespace).Get(name)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			glog.Errorf("Failed to get job <%s>: %v", key, err)
+			cc.ttlQueue.AddRateLimited(key)
+			return true
+		}
+		cc.ttlQueue.Forget(key)
+		return true
+	}
+
+	expired, remaining := ttlExpired(job)
+	if !expired {
+		if remaining > 0 {
+			cc.ttlQueue.AddAfter(key, remaining)
+		}
+		cc.ttlQueue.Forget(key)
+		return true
+	}
+
+	glog.V(3).Infof("Deleting finished Job <%s> as its TTL expired.", key)
+
+	// Make sure a re-created Job with the same name will not be deleted.
+	uid := job.UID
+	if err := cc.vkClients.BatchV1alpha1().Jobs(namespace).Delete(name, &metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &uid},
+	}); err != nil && !apierrors.IsNotFound(err) {
+		glog.Errorf("Failed to delete finished Job <%s>: %v", key, err)
+		cc.ttlQueue.AddRateLimited(key)
+		return true
+	}
+
+	cc.ttlQueue.Forget(key)
+	return true
+}
+
 func (cc *Controller) updatePodGroup(oldObj, newObj interface{}) {
 	oldPG, ok := oldObj.(*kbtype.PodGroup)
 	if !ok {
@@ -363,7 +492,7 @@ func (cc *Controller) updatePodGroup(oldObj, newObj interface{}) {
 		return
 	}
 
-	_, err := cc.cache.Get(vkcache.JobKeyByName(newPG.Namespace, newPG.Name))
+	jobInfo, err := cc.cache.Get(vkcache.JobKeyByName(newPG.Namespace, newPG.Name))
 	if err != nil {
 		glog.Warningf(
 			"Failed to find job in cache by PodGroup, this may not be a PodGroup for volcano job.")
@@ -377,6 +506,15 @@ func (cc *Controller) updatePodGroup(oldObj, newObj interface{}) {
 		}
 		cc.queue.Add(req)
 	}
-}
 
-// TODO(k82cn): add handler for PodGroup unschedulable event.
+	if err == nil && podGroupUnschedulable(newPG) && !podGroupUnschedulable(oldPG) {
+		req := apis.Request{
+			Namespace: newPG.Namespace,
+			JobName:   newPG.Name,
+
+			Event:      vkbatchv1.JobUnschedulableEvent,
+			JobVersion: jobInfo.Job.Status.Version,
+		}
+		cc.queue.Add(req)
+	}
+}
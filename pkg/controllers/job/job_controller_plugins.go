@@ -86,3 +86,65 @@ func (cc *Controller) pluginOnJobDelete(job *vkv1.Job) error {
 
 	return nil
 }
+
+// pluginOnJobUpdate calls OnJobUpdate of plugins once the spec of Job is
+// updated, i.e. its generation is changed since the last sync.
+func (cc *Controller) pluginOnJobUpdate(job *vkv1.Job) error {
+	if observed := job.Status.ObservedGeneration; observed != 0 && observed != job.Generation {
+		client := vkinterface.PluginClientset{KubeClients: cc.kubeClients}
+		for name, args := range job.Spec.Plugins {
+			if pb, found := vkplugin.GetPluginBuilder(name); !found {
+				err := fmt.Errorf("failed to get plugin %s", name)
+				glog.Error(err)
+				return err
+			} else {
+				glog.Infof("Starting to execute plugin at <pluginOnJobUpdate>: %s on job: <%s/%s>", name, job.Namespace, job.Name)
+				if err := pb(client, args).OnJobUpdate(job); err != nil {
+					glog.Errorf("Failed to process on job update plugin %s, err %v.", name, err)
+					return err
+				}
+			}
+		}
+	}
+
+	job.Status.ObservedGeneration = job.Generation
+
+	return nil
+}
+
+func (cc *Controller) pluginOnPodDelete(job *vkv1.Job, pod *v1.Pod) error {
+	client := vkinterface.PluginClientset{KubeClients: cc.kubeClients}
+	for name, args := range job.Spec.Plugins {
+		if pb, found := vkplugin.GetPluginBuilder(name); !found {
+			err := fmt.Errorf("failed to get plugin %s", name)
+			glog.Error(err)
+			return err
+		} else {
+			glog.Infof("Starting to execute plugin at <pluginOnPodDelete>: %s on job: <%s/%s>", name, job.Namespace, job.Name)
+			if err := pb(client, args).OnPodDelete(pod, job); err != nil {
+				glog.Errorf("Failed to process on pod delete plugin %s, err %v.", name, err)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (cc *Controller) pluginOnJobRestart(job *vkv1.Job, oldVersion, newVersion int32) error {
+	client := vkinterface.PluginClientset{KubeClients: cc.kubeClients}
+	for name, args := range job.Spec.Plugins {
+		if pb, found := vkplugin.GetPluginBuilder(name); !found {
+			err := fmt.Errorf("failed to get plugin %s", name)
+			glog.Error(err)
+			return err
+		} else {
+			glog.Infof("Starting to execute plugin at <pluginOnJobRestart>: %s on job: <%s/%s>", name, job.Namespace, job.Name)
+			if err := pb(client, args).OnJobRestart(job, oldVersion, newVersion); err != nil {
+				glog.Errorf("Failed to process on job restart plugin %s, err %v.", name, err)
+				return err
+			}
+		}
+	}
+
+	return nil
+}
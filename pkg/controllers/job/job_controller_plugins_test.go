@@ -0,0 +1,158 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	vkv1 "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
+	vkplugin "volcano.sh/volcano/pkg/controllers/job/plugins"
+	vkinterface "volcano.sh/volcano/pkg/controllers/job/plugins/interface"
+)
+
+const recordingPluginName = "test-recording"
+
+// recordingPlugin counts the calls of the hooks which are not called for every sync.
+type recordingPlugin struct {
+	vkinterface.BasePlugin
+
+	calls map[string]int
+}
+
+func (rp *recordingPlugin) Name() string {
+	return recordingPluginName
+}
+
+func (rp *recordingPlugin) OnPodCreate(pod *v1.Pod, job *vkv1.Job) error {
+	return nil
+}
+
+func (rp *recordingPlugin) OnJobAdd(job *vkv1.Job) error {
+	return nil
+}
+
+func (rp *recordingPlugin) OnJobUpdate(job *vkv1.Job) error {
+	rp.calls["OnJobUpdate"]++
+	return nil
+}
+
+func (rp *recordingPlugin) OnPodDelete(pod *v1.Pod, job *vkv1.Job) error {
+	rp.calls["OnPodDelete"]++
+	return nil
+}
+
+func (rp *recordingPlugin) OnJobRestart(job *vkv1.Job, oldVersion, newVersion int32) error {
+	rp.calls["OnJobRestart"]++
+	return nil
+}
+
+func newRecordingPlugin() *recordingPlugin {
+	rp := &recordingPlugin{calls: map[string]int{}}
+	vkplugin.RegisterPluginBuilder(recordingPluginName,
+		func(client vkinterface.PluginClientset, arguments []string) vkinterface.PluginInterface {
+			return rp
+		})
+
+	return rp
+}
+
+func newPluginTestJob() *vkv1.Job {
+	return &vkv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  "test",
+			Name:       "job",
+			Generation: 1,
+		},
+		Spec: vkv1.JobSpec{
+			Tasks: []vkv1.TaskSpec{
+				{Name: "worker", Replicas: 2},
+			},
+			Plugins: map[string][]string{
+				recordingPluginName: {},
+			},
+		},
+	}
+}
+
+func TestPluginOnJobUpdateGatedByObservedGeneration(t *testing.T) {
+	rp := newRecordingPlugin()
+	cc := &Controller{}
+	job := newPluginTestJob()
+
+	// The first sync of Job only observes its generation.
+	if err := cc.pluginOnJobUpdate(job); err != nil {
+		t.Fatalf("failed to call OnJobUpdate: %v", err)
+	}
+	if rp.calls["OnJobUpdate"] != 0 {
+		t.Errorf("expected no OnJobUpdate at the first sync, got %d", rp.calls["OnJobUpdate"])
+	}
+	if job.Status.ObservedGeneration != job.Generation {
+		t.Errorf("expected observed generation %d, got %d", job.Generation, job.Status.ObservedGeneration)
+	}
+
+	// Scale the Job, its generation is bumped by apiserver.
+	job.Spec.Tasks[0].Replicas = 4
+	job.Generation++
+
+	for i := 0; i < 2; i++ {
+		if err := cc.pluginOnJobUpdate(job); err != nil {
+			t.Fatalf("failed to call OnJobUpdate: %v", err)
+		}
+	}
+	if rp.calls["OnJobUpdate"] != 1 {
+		t.Errorf("expected OnJobUpdate once after scaling, got %d", rp.calls["OnJobUpdate"])
+	}
+	if job.Status.ObservedGeneration != job.Generation {
+		t.Errorf("expected observed generation %d, got %d", job.Generation, job.Status.ObservedGeneration)
+	}
+}
+
+func TestPluginOnPodDelete(t *testing.T) {
+	rp := newRecordingPlugin()
+	cc := &Controller{}
+	job := newPluginTestJob()
+	pod := createJobPod(job, &job.Spec.Tasks[0].Template, 0)
+
+	if err := cc.pluginOnPodDelete(job, pod); err != nil {
+		t.Fatalf("failed to call OnPodDelete: %v", err)
+	}
+	if rp.calls["OnPodDelete"] != 1 {
+		t.Errorf("expected OnPodDelete once, got %d", rp.calls["OnPodDelete"])
+	}
+	if rp.calls["OnJobUpdate"] != 0 || rp.calls["OnJobRestart"] != 0 {
+		t.Errorf("expected no other hooks, got %v", rp.calls)
+	}
+}
+
+func TestPluginOnJobRestart(t *testing.T) {
+	rp := newRecordingPlugin()
+	cc := &Controller{}
+	job := newPluginTestJob()
+
+	if err := cc.pluginOnJobRestart(job, 0, 1); err != nil {
+		t.Fatalf("failed to call OnJobRestart: %v", err)
+	}
+	if rp.calls["OnJobRestart"] != 1 {
+		t.Errorf("expected OnJobRestart once, got %d", rp.calls["OnJobRestart"])
+	}
+	if rp.calls["OnJobUpdate"] != 0 || rp.calls["OnPodDelete"] != 0 {
+		t.Errorf("expected no other hooks, got %v", rp.calls)
+	}
+}
@@ -18,6 +18,11 @@ package job
 
 import (
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/golang/glog"
 	vkjobhelpers "volcano.sh/volcano/pkg/controllers/job/helpers"
 
@@ -30,8 +35,38 @@ import (
 	vkv1 "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
 	"volcano.sh/volcano/pkg/apis/helpers"
 	"volcano.sh/volcano/pkg/controllers/apis"
+	"volcano.sh/volcano/pkg/controllers/job/state"
+)
+
+const (
+	defaultRestartBackoffFactor = 2
+
+	// The maximal number of failures recorded in 'lastFailures' of Job.
+	maxLastFailures = 5
+	// The maximal number of phase transitions recorded in 'conditions' of Job.
+	maxJobConditions = 20
+	// The maximal number of lines of termination message or log recorded for failure.
+	maxFailureMessageLines = 10
+	// The timeout to get the log of failed container from kubelet, and to record it
+	// into the failure persisted in Job status.
+	failureLogTimeout = 5 * time.Second
+
+	// The reason of Pod set by node controller if its node is unreachable.
+	podReasonNodeLost = "NodeLost"
+	// The reason of Pod set by kubelet if it's preempted by a critical Pod.
+	podReasonPreempting = "Preempting"
+	// The reason of container set by kubelet if it's OOM killed.
+	containerReasonOOMKilled = "OOMKilled"
 )
 
+// The events triggered by failed pods, which may be tolerated by 'maxFailed'.
+var podFailureEvents = map[vkv1.Event]bool{
+	vkv1.PodFailedEvent:    true,
+	vkv1.PodOOMKilledEvent: true,
+	vkv1.NodeLostEvent:     true,
+	vkv1.PodPreemptedEvent: true,
+}
+
 func eventKey(obj interface{}) interface{} {
 	req, ok := obj.(apis.Request)
 	if !ok {
@@ -48,12 +83,52 @@ func MakePodName(jobName string, taskName string, index int) string {
 	return fmt.Sprintf(vkjobhelpers.TaskNameFmt, jobName, taskName, index)
 }
 
+// jobPodName returns the name of the pod of Job. If the failed pods are retained,
+// the pods re-created after restart are suffixed by the version of Job and task,
+// so they don't collide with the retained ones.
+func jobPodName(job *vkv1.Job, taskName string, index int) string {
+	name := MakePodName(job.Name, taskName, index)
+	if job.Spec.RetentionPolicy == nil {
+		return name
+	}
+
+	version, taskVersion := job.Status.Version, job.Status.TaskVersions[taskName]
+	if version == 0 && taskVersion == 0 {
+		return name
+	}
+
+	return fmt.Sprintf("%s-%d-%d", name, version, taskVersion)
+}
+
+// podRetained returns whether the pod is retained for debugging.
+func podRetained(pod *v1.Pod) bool {
+	return pod.Labels[vkv1.RetainedPodKey] == "true"
+}
+
+// podScaledDown returns whether the pod is deleted by controller as it's not desired
+// by Job any more, see deleteScaledDownPod.
+func podScaledDown(pod *v1.Pod) bool {
+	return pod.Annotations[vkv1.ScaledDownPodKey] == "true"
+}
+
+// ownedBy returns whether the object is owned by the Job, no matter it's the
+// controller or not.
+func ownedBy(obj metav1.Object, job *vkv1.Job) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == job.UID {
+			return true
+		}
+	}
+
+	return false
+}
+
 func createJobPod(job *vkv1.Job, template *v1.PodTemplateSpec, ix int) *v1.Pod {
 	templateCopy := template.DeepCopy()
 
 	pod := &v1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      MakePodName(job.Name, template.Name, ix),
+			Name:      jobPodName(job, template.Name, ix),
 			Namespace: job.Namespace,
 			OwnerReferences: []metav1.OwnerReference{
 				*metav1.NewControllerRef(job, helpers.JobKind),
@@ -64,6 +139,11 @@ func createJobPod(job *vkv1.Job, template *v1.PodTemplateSpec, ix int) *v1.Pod {
 		Spec: templateCopy.Spec,
 	}
 
+	// Keep the hostname of the suffixed pod as the other pods of Job know it.
+	if podName := MakePodName(job.Name, template.Name, ix); pod.Name != podName && len(pod.Spec.Hostname) == 0 {
+		pod.Spec.Hostname = podName
+	}
+
 	// If no scheduler name in Pod, use scheduler name from Job.
 	if len(pod.Spec.SchedulerName) == 0 {
 		pod.Spec.SchedulerName = job.Spec.SchedulerName
@@ -144,6 +224,8 @@ func createJobPod(job *vkv1.Job, template *v1.PodTemplateSpec, ix int) *v1.Pod {
 	pod.Annotations[kbapi.GroupNameAnnotationKey] = job.Name
 	pod.Annotations[vkv1.JobNameKey] = job.Name
 	pod.Annotations[vkv1.JobVersion] = fmt.Sprintf("%d", job.Status.Version)
+	pod.Annotations[vkv1.TaskVersion] = fmt.Sprintf("%d", job.Status.TaskVersions[template.Name])
+	pod.Annotations[vkv1.TaskIndex] = strconv.Itoa(ix)
 
 	if len(pod.Labels) == 0 {
 		pod.Labels = make(map[string]string)
@@ -152,6 +234,7 @@ func createJobPod(job *vkv1.Job, template *v1.PodTemplateSpec, ix int) *v1.Pod {
 	// Set pod labels for Service.
 	pod.Labels[vkv1.JobNameKey] = job.Name
 	pod.Labels[vkv1.JobNamespaceKey] = job.Namespace
+	pod.Labels[vkv1.TaskSpecKey] = tsKey
 
 	// we fill the schedulerName in the pod definition with the one specified in the QJ template
 	if job.Spec.SchedulerName != "" && pod.Spec.SchedulerName == "" {
@@ -161,19 +244,37 @@ func createJobPod(job *vkv1.Job, template *v1.PodTemplateSpec, ix int) *v1.Pod {
 	return pod
 }
 
-func applyPolicies(job *vkv1.Job, req *apis.Request) vkv1.Action {
+// applyPolicies returns the action for the request, and the timeout of the
+// matched LifecyclePolicy if the action should be delayed.
+func applyPolicies(jobInfo *apis.JobInfo, req *apis.Request) (vkv1.Action, *metav1.Duration) {
+	job := jobInfo.Job
+
 	if len(req.Action) != 0 {
-		return req.Action
+		return req.Action, nil
 	}
 
 	if req.Event == vkv1.OutOfSyncEvent {
-		return vkv1.SyncJobAction
+		return vkv1.SyncJobAction, nil
 	}
 
 	// For all the requests triggered from discarded job resources will perform sync action instead
 	if req.JobVersion < job.Status.Version {
 		glog.Infof("Request %s is outdated, will perform sync instead.", req)
-		return vkv1.SyncJobAction
+		return vkv1.SyncJobAction, nil
+	}
+
+	// The requests triggered from the pods of a restarted task are outdated too
+	if len(req.TaskName) != 0 && req.TaskVersion < job.Status.TaskVersions[req.TaskName] {
+		glog.Infof("Request %s is outdated as task was restarted, will perform sync instead.", req)
+		return vkv1.SyncJobAction, nil
+	}
+
+	// The failed pods are ignored until 'maxFailed' of task or Job is exceeded; the deleted
+	// pods, e.g. on lost nodes, are re-created instead of kept failed, so never tolerated.
+	if podFailureEvents[req.Event] && req.BaseEvent != vkv1.PodEvictedEvent &&
+		failureTolerated(jobInfo, req.TaskName) {
+		glog.Infof("Failure in request %s is tolerated, will perform sync instead.", req)
+		return vkv1.SyncJobAction, nil
 	}
 
 	// Overwrite Job level policies
@@ -181,15 +282,8 @@ func applyPolicies(job *vkv1.Job, req *apis.Request) vkv1.Action {
 		// Parse task level policies
 		for _, task := range job.Spec.Tasks {
 			if task.Name == req.TaskName {
-				for _, policy := range task.Policies {
-					if policy.Event == req.Event || policy.Event == vkv1.AnyEvent {
-						return policy.Action
-					}
-
-					// 0 is not an error code, is prevented in validation admission controller
-					if policy.ExitCode != nil && *policy.ExitCode == req.ExitCode {
-						return policy.Action
-					}
+				if policy := matchPolicy(task.Policies, req); policy != nil {
+					return policy.Action, policy.Timeout
 				}
 				break
 			}
@@ -197,16 +291,647 @@ func applyPolicies(job *vkv1.Job, req *apis.Request) vkv1.Action {
 	}
 
 	// Parse Job level policies
-	for _, policy := range job.Spec.Policies {
-		if policy.Event == req.Event || policy.Event == vkv1.AnyEvent {
-			return policy.Action
-		}
+	if policy := matchPolicy(job.Spec.Policies, req); policy != nil {
+		return policy.Action, policy.Timeout
+	}
+
+	return vkv1.SyncJobAction, nil
+}
+
+// matchPolicy returns the policy matched by the request, the precedence is:
+// 1. the policy matched by the exit code,
+// 2. the policy matched by the event,
+// 3. the policy matched by the base event, e.g. PodEvicted for NodeLost,
+// 4. the policy of any event ('*').
+func matchPolicy(policies []vkv1.LifecyclePolicy, req *apis.Request) *vkv1.LifecyclePolicy {
+	var eventMatched, baseEventMatched, anyEventMatched *vkv1.LifecyclePolicy
+
+	for i := range policies {
+		policy := &policies[i]
 
 		// 0 is not an error code, is prevented in validation admission controller
-		if policy.ExitCode != nil && *policy.ExitCode == req.ExitCode {
-			return policy.Action
+		if req.ExitCode != 0 && exitCodeMatched(policy, req.ExitCode) {
+			return policy
+		}
+
+		for _, event := range policyEvents(policy) {
+			switch event {
+			case req.Event:
+				if eventMatched == nil {
+					eventMatched = policy
+				}
+			case req.BaseEvent:
+				if baseEventMatched == nil {
+					baseEventMatched = policy
+				}
+			case vkv1.AnyEvent:
+				if anyEventMatched == nil {
+					anyEventMatched = policy
+				}
+			}
+		}
+	}
+
+	if eventMatched != nil {
+		return eventMatched
+	}
+
+	if baseEventMatched != nil {
+		return baseEventMatched
+	}
+
+	return anyEventMatched
+}
+
+// policyEvents returns all the events of policy.
+func policyEvents(policy *vkv1.LifecyclePolicy) []vkv1.Event {
+	if len(policy.Event) == 0 {
+		return policy.Events
+	}
+
+	return append([]vkv1.Event{policy.Event}, policy.Events...)
+}
+
+// exitCodeMatched checks whether the non-zero exit code is matched by the policy.
+func exitCodeMatched(policy *vkv1.LifecyclePolicy, exitCode int32) bool {
+	if policy.ExitCode != nil && *policy.ExitCode == exitCode {
+		return true
+	}
+
+	if policy.ExitCodes == nil {
+		return false
+	}
+
+	found := exitCodeInRequirement(policy.ExitCodes, exitCode)
+	if policy.ExitCodes.Operator == vkv1.ExitCodeOpNotIn {
+		return !found
+	}
+
+	return found
+}
+
+func exitCodeInRequirement(requirement *vkv1.ExitCodeRequirement, exitCode int32) bool {
+	for _, value := range requirement.Values {
+		if value == exitCode {
+			return true
+		}
+	}
+
+	for _, r := range requirement.Ranges {
+		if r.Min <= exitCode && exitCode <= r.Max {
+			return true
+		}
+	}
+
+	return false
+}
+
+// failureTolerated returns whether the failed pods of the task are tolerated
+// by 'maxFailed' of task and Job.
+func failureTolerated(jobInfo *apis.JobInfo, taskName string) bool {
+	taskStatuses := map[string]vkv1.TaskStatus{}
+	for name, pods := range jobInfo.Pods {
+		var taskStatus vkv1.TaskStatus
+		for _, pod := range pods {
+			if pod.DeletionTimestamp == nil && pod.Status.Phase == v1.PodFailed {
+				taskStatus.Failed++
+			}
+		}
+		taskStatuses[name] = taskStatus
+	}
+
+	countToleratedFailures(jobInfo.Job, taskStatuses)
+
+	return taskStatuses[taskName].Tolerated != 0
+}
+
+// countToleratedFailures sets the tolerated failed pods of each task in taskStatuses,
+// and returns the total number of them. The failed pods of a task are tolerated if
+// neither 'maxFailed' of the task nor 'maxFailed' of Job is exceeded, and at least
+// one of them is specified.
+func countToleratedFailures(job *vkv1.Job, taskStatuses map[string]vkv1.TaskStatus) int32 {
+	var failed, tolerated int32
+	for _, taskStatus := range taskStatuses {
+		failed += taskStatus.Failed
+	}
+
+	if job.Spec.MaxFailed != nil && failed > *job.Spec.MaxFailed {
+		return 0
+	}
+
+	for _, task := range job.Spec.Tasks {
+		taskStatus, found := taskStatuses[task.Name]
+		if !found || taskStatus.Failed == 0 {
+			continue
+		}
+		if task.MaxFailed == nil && job.Spec.MaxFailed == nil {
+			continue
+		}
+		if task.MaxFailed != nil && taskStatus.Failed > *task.MaxFailed {
+			continue
+		}
+
+		taskStatus.Tolerated = taskStatus.Failed
+		taskStatuses[task.Name] = taskStatus
+		tolerated += taskStatus.Failed
+	}
+
+	return tolerated
+}
+
+// teardownStage returns the stage to kill the pods of task by 'taskOrder' of
+// termination policy; the tasks not in the order are killed in the first stage.
+func teardownStage(job *vkv1.Job, taskName string) int {
+	if policy := job.Spec.TerminationPolicy; policy != nil {
+		for i, name := range policy.TaskOrder {
+			if name == taskName {
+				return i + 1
+			}
+		}
+	}
+
+	return 0
+}
+
+// currentTeardownStage returns the first stage which still has pods to kill.
+func currentTeardownStage(jobInfo *apis.JobInfo) int {
+	stage := math.MaxInt32
+	for taskName, pods := range jobInfo.Pods {
+		if s := teardownStage(jobInfo.Job, taskName); len(pods) != 0 && s < stage {
+			stage = s
+		}
+	}
+
+	return stage
+}
+
+// delayedActionCancelled checks whether the condition which triggered the delayed
+// action was cleared, e.g. the evicted pod was re-created and is running again.
+func delayedActionCancelled(jobInfo *apis.JobInfo, pg *kbapi.PodGroup, req *apis.Request) bool {
+	// The action was taken or cleared by another request of it, e.g. it's re-armed.
+	if findDelayedAction(jobInfo.Job, req, req.Action) < 0 {
+		return true
+	}
+
+	// The job was restarted/killed since the action was scheduled.
+	if req.JobVersion < jobInfo.Job.Status.Version {
+		return true
+	}
+
+	// The task was restarted since the action was scheduled.
+	if req.TaskVersion < jobInfo.Job.Status.TaskVersions[req.TaskName] {
+		return true
+	}
+
+	if req.Event == vkv1.JobUnschedulableEvent {
+		return jobScheduled(jobInfo, pg)
+	}
+
+	return podRecovered(jobInfo, req.TaskName, req.PodName)
+}
+
+// jobScheduled returns true if the PodGroup of Job is not unschedulable anymore,
+// or the minimal available pods of Job are running.
+func jobScheduled(jobInfo *apis.JobInfo, pg *kbapi.PodGroup) bool {
+	if pg != nil && !podGroupUnschedulable(pg) {
+		return true
+	}
+
+	return jobInfo.Job.Status.Running >= jobInfo.Job.Spec.MinAvailable
+}
+
+// podGroupUnschedulable returns true if the PodGroup has an Unschedulable condition.
+func podGroupUnschedulable(pg *kbapi.PodGroup) bool {
+	for _, cond := range pg.Status.Conditions {
+		if cond.Type == kbapi.PodGroupUnschedulableType && cond.Status == v1.ConditionTrue {
+			return true
+		}
+	}
+
+	return false
+}
+
+// podRecovered returns true if the pod is running; it's false for the
+// actions which were not triggered by a pod.
+func podRecovered(jobInfo *apis.JobInfo, taskName, podName string) bool {
+	if len(podName) == 0 {
+		return false
+	}
+
+	pod, found := jobInfo.Pods[taskName][podName]
+	if !found {
+		return false
+	}
+
+	return pod.DeletionTimestamp == nil && pod.Status.Phase == v1.PodRunning
+}
+
+// findDelayedAction returns the index of the delayed action in job status
+// which was scheduled by the request, or -1 if not found.
+func findDelayedAction(job *vkv1.Job, req *apis.Request, action vkv1.Action) int {
+	for i, da := range job.Status.DelayedActions {
+		if da.Action == action && da.Event == req.Event &&
+			da.TaskName == req.TaskName && da.PodName == req.PodName {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// removeDelayedAction removes the delayed action which was scheduled by the request from job status.
+func removeDelayedAction(job *vkv1.Job, req *apis.Request) {
+	idx := findDelayedAction(job, req, req.Action)
+	if idx < 0 {
+		return
+	}
+
+	var das []vkv1.DelayedAction
+	for i, da := range job.Status.DelayedActions {
+		if i != idx {
+			das = append(das, da)
+		}
+	}
+	job.Status.DelayedActions = das
+}
+
+// pendingDelayedActions returns the delayed actions whose triggering condition still holds.
+func pendingDelayedActions(jobInfo *apis.JobInfo) []vkv1.DelayedAction {
+	var pending []vkv1.DelayedAction
+	for _, da := range jobInfo.Job.Status.DelayedActions {
+		if podRecovered(jobInfo, da.TaskName, da.PodName) {
+			glog.V(3).Infof("Delayed action <%s> of Job <%s/%s> was cancelled, pod <%s> is running.",
+				da.Action, jobInfo.Namespace, jobInfo.Name, da.PodName)
+			continue
 		}
+		pending = append(pending, da)
 	}
 
-	return vkv1.SyncJobAction
+	return pending
+}
+
+// updateJobState sets the next state of Job, and keeps the bookkeeping of
+// the phase transition in Job status.
+func updateJobState(job *vkv1.Job, nextState state.NextStateFn) {
+	if nextState == nil {
+		return
+	}
+
+	oldPhase := job.Status.State.Phase
+	job.Status.State = nextState(job.Status)
+	if job.Status.State.Phase == oldPhase {
+		return
+	}
+
+	// Keep the latest transitions only, so the status of a Job which is
+	// restarted again and again doesn't grow without bound.
+	conditions := append(job.Status.Conditions, vkv1.JobCondition{
+		Type:               job.Status.State.Phase,
+		Status:             v1.ConditionTrue,
+		Reason:             job.Status.State.Reason,
+		Message:            job.Status.State.Message,
+		LastTransitionTime: metav1.Now(),
+	})
+	if len(conditions) > maxJobConditions {
+		conditions = conditions[len(conditions)-maxJobConditions:]
+	}
+	job.Status.Conditions = conditions
+
+	if jobFinished(job.Status.State.Phase) {
+		if job.Status.FinishTime == nil {
+			now := metav1.Now()
+			job.Status.FinishTime = &now
+		}
+	} else {
+		// e.g. the aborted Job was resumed.
+		job.Status.FinishTime = nil
+	}
+
+	switch job.Status.State.Phase {
+	case vkv1.Restarting:
+		// Only count the restarts of a living Job, e.g. resuming an aborted Job is not a retry.
+		if oldPhase == vkv1.Running || oldPhase == vkv1.Pending {
+			job.Status.RetryCount++
+			if job.Spec.RestartBackoff != nil {
+				next := metav1.NewTime(time.Now().Add(restartDelay(job.Spec.RestartBackoff, job.Status.RetryCount)))
+				job.Status.NextRestartTime = &next
+			}
+		}
+	}
+}
+
+// restartDelay returns the backoff before the pods of Job are re-created for
+// the 'retry'th time: initialDelay * factor^(retry-1), limited by maxDelay.
+func restartDelay(backoff *vkv1.RestartBackoff, retry int32) time.Duration {
+	factor := time.Duration(backoff.Factor)
+	if factor <= 0 {
+		factor = defaultRestartBackoffFactor
+	}
+
+	delay := backoff.InitialDelay.Duration
+	for i := int32(1); i < retry; i++ {
+		if delay > math.MaxInt64/factor {
+			delay = math.MaxInt64
+			break
+		}
+		delay = delay * factor
+	}
+
+	if backoff.MaxDelay != nil && delay > backoff.MaxDelay.Duration {
+		delay = backoff.MaxDelay.Duration
+	}
+
+	return delay
+}
+
+// jobFinished returns true if Job will not run anymore unless it's resumed.
+func jobFinished(phase vkv1.JobPhase) bool {
+	switch phase {
+	case vkv1.Completed, vkv1.Terminated, vkv1.Aborted, vkv1.Failed:
+		return true
+	}
+
+	return false
+}
+
+// ttlExpired checks whether the TTL of the finished Job expired; if not, it
+// also returns the remaining time. It's false if the Job has no TTL or is not finished.
+func ttlExpired(job *vkv1.Job) (bool, time.Duration) {
+	if job.DeletionTimestamp != nil || job.Spec.TTLSecondsAfterFinished == nil ||
+		job.Status.FinishTime == nil || !jobFinished(job.Status.State.Phase) {
+		return false, 0
+	}
+
+	ttl := time.Duration(*job.Spec.TTLSecondsAfterFinished) * time.Second
+	remaining := job.Status.FinishTime.Add(ttl).Sub(time.Now())
+	if remaining <= 0 {
+		return true, 0
+	}
+
+	return false, remaining
+}
+
+// dependenciesSatisfied checks whether all pods of the tasks that the task
+// depends on reach the condition of the dependency.
+func dependenciesSatisfied(jobInfo *apis.JobInfo, task *vkv1.TaskSpec) bool {
+	for _, dep := range task.DependsOn {
+		var replicas, satisfied int32
+		for _, ts := range jobInfo.Job.Spec.Tasks {
+			if ts.Name == dep.Name {
+				replicas = ts.Replicas
+				break
+			}
+		}
+
+		for _, pod := range jobInfo.Pods[dep.Name] {
+			if pod.DeletionTimestamp != nil {
+				continue
+			}
+
+			switch dependencyCondition(dep) {
+			case vkv1.DependencySucceeded:
+				if pod.Status.Phase == v1.PodSucceeded {
+					satisfied++
+				}
+			case vkv1.DependencyRunning:
+				if pod.Status.Phase == v1.PodRunning || pod.Status.Phase == v1.PodSucceeded {
+					satisfied++
+				}
+			}
+		}
+
+		if satisfied < replicas {
+			glog.V(3).Infof("Task <%s> of Job <%s/%s> is waiting for task <%s> to be %s: %d/%d.",
+				task.Name, jobInfo.Namespace, jobInfo.Name, dep.Name, dependencyCondition(dep), satisfied, replicas)
+			return false
+		}
+	}
+
+	return true
+}
+
+func dependencyCondition(dep vkv1.TaskDependency) vkv1.DependencyCondition {
+	if len(dep.Condition) == 0 {
+		return vkv1.DependencyRunning
+	}
+	return dep.Condition
+}
+
+// podExitCode returns the exit code of failed pod: the exit code of the main
+// container if it's specified by annotation, otherwise the first non-zero
+// exit code of init containers and containers.
+func podExitCode(pod *v1.Pod) int32 {
+	if status := failedContainer(pod); status != nil {
+		return containerExitCode(*status)
+	}
+
+	return 0
+}
+
+// failedContainer returns the status of the container which makes the pod
+// failed, see podExitCode; nil if not found.
+func failedContainer(pod *v1.Pod) *v1.ContainerStatus {
+	if name, found := pod.Annotations[vkv1.MainContainerKey]; found {
+		for i, status := range pod.Status.ContainerStatuses {
+			if status.Name == name {
+				return &pod.Status.ContainerStatuses[i]
+			}
+		}
+		glog.Warningf("Failed to find main container %s of Pod <%s/%s>",
+			name, pod.Namespace, pod.Name)
+	}
+
+	for _, statuses := range [][]v1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for i := range statuses {
+			if containerExitCode(statuses[i]) != 0 {
+				return &statuses[i]
+			}
+		}
+	}
+
+	return nil
+}
+
+// newPodFailure returns the failure of pod which fires the action.
+func newPodFailure(pod *v1.Pod, req *apis.Request, action vkv1.Action) vkv1.PodFailure {
+	failure := vkv1.PodFailure{
+		PodName:  pod.Name,
+		PodUID:   pod.UID,
+		TaskName: req.TaskName,
+		Reason:   pod.Status.Reason,
+		Message:  lastLines(pod.Status.Message, maxFailureMessageLines),
+		Event:    req.Event,
+		Action:   action,
+		Time:     metav1.Now(),
+	}
+
+	if status := failedContainer(pod); status != nil {
+		failure.ContainerName = status.Name
+		if terminated := containerTermination(*status); terminated != nil {
+			failure.ExitCode = terminated.ExitCode
+			if len(terminated.Reason) != 0 {
+				failure.Reason = terminated.Reason
+			}
+			if len(terminated.Message) != 0 {
+				failure.Message = lastLines(terminated.Message, maxFailureMessageLines)
+			}
+		}
+	}
+
+	return failure
+}
+
+// podFailureRecorded returns whether the failure of pod is already recorded in
+// 'lastFailures' of Job, e.g. the action was retried after an error.
+func podFailureRecorded(job *vkv1.Job, pod *v1.Pod) bool {
+	for _, failure := range job.Status.LastFailures {
+		if failure.PodUID == pod.UID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// appendPodFailure appends the failure to 'lastFailures' of Job, and drops
+// the oldest ones if 'maxLastFailures' is exceeded.
+func appendPodFailure(job *vkv1.Job, failure vkv1.PodFailure) {
+	failures := append(job.Status.LastFailures, failure)
+	if len(failures) > maxLastFailures {
+		failures = failures[len(failures)-maxLastFailures:]
+	}
+	job.Status.LastFailures = failures
+}
+
+// lastLines returns the last n lines of the text.
+func lastLines(text string, n int) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// podFailedEvent classifies the failure of pod by the markers of preemption,
+// the reason of pod and the termination reason of its containers.
+func podFailedEvent(pod *v1.Pod) vkv1.Event {
+	switch {
+	case podPreempted(pod):
+		return vkv1.PodPreemptedEvent
+	case pod.Status.Reason == podReasonNodeLost:
+		return vkv1.NodeLostEvent
+	case podOOMKilled(pod):
+		return vkv1.PodOOMKilledEvent
+	}
+
+	return vkv1.PodFailedEvent
+}
+
+// podPreempted returns whether the pod was preempted by kubelet for a critical
+// pod. The pods evicted by scheduler are deleted without any marker, so they're
+// reported as PodEvicted.
+func podPreempted(pod *v1.Pod) bool {
+	return pod.Status.Reason == podReasonPreempting
+}
+
+// podOOMKilled returns whether the main container of pod was OOM killed; if no
+// main container is specified, whether any of its containers was OOM killed.
+func podOOMKilled(pod *v1.Pod) bool {
+	if name, found := pod.Annotations[vkv1.MainContainerKey]; found {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name == name {
+				return containerTerminationReason(status) == containerReasonOOMKilled
+			}
+		}
+	}
+
+	for _, statuses := range [][]v1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, status := range statuses {
+			if containerTerminationReason(status) == containerReasonOOMKilled {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// containerTermination returns the last termination of container, nil if it
+// has never been terminated.
+func containerTermination(status v1.ContainerStatus) *v1.ContainerStateTerminated {
+	if status.State.Terminated != nil {
+		return status.State.Terminated
+	}
+
+	return status.LastTerminationState.Terminated
+}
+
+// containerTerminationReason returns the reason of the last termination of
+// container, empty if it has never been terminated.
+func containerTerminationReason(status v1.ContainerStatus) string {
+	if terminated := containerTermination(status); terminated != nil {
+		return terminated.Reason
+	}
+
+	return ""
+}
+
+// containerExitCode returns the exit code of the last termination of container,
+// 0 if it has never been terminated.
+func containerExitCode(status v1.ContainerStatus) int32 {
+	if terminated := containerTermination(status); terminated != nil {
+		return terminated.ExitCode
+	}
+
+	return 0
+}
+
+// podTaskVersion returns the version of task when the pod was created, it's 0
+// for the pods created before task version was introduced.
+func podTaskVersion(pod *v1.Pod) int32 {
+	version, found := pod.Annotations[vkv1.TaskVersion]
+	if !found {
+		return 0
+	}
+
+	dVersion, err := strconv.Atoi(version)
+	if err != nil {
+		glog.Infof("Failed to convert taskVersion of Pod <%s/%s> into number: %v",
+			pod.Namespace, pod.Name, err)
+		return 0
+	}
+
+	return int32(dVersion)
+}
+
+// podRequests returns the resource requests of pod: the larger one of the total
+// requests of containers and the requests of each init container.
+func podRequests(spec *v1.PodSpec) v1.ResourceList {
+	requests := v1.ResourceList{}
+	for _, c := range spec.Containers {
+		addResourceList(requests, c.Resources.Requests)
+	}
+
+	for _, c := range spec.InitContainers {
+		for name, quantity := range c.Resources.Requests {
+			if value, found := requests[name]; !found || quantity.Cmp(value) > 0 {
+				requests[name] = quantity.DeepCopy()
+			}
+		}
+	}
+
+	return requests
+}
+
+// addResourceList adds the resources in delta to list.
+func addResourceList(list, delta v1.ResourceList) {
+	for name, quantity := range delta {
+		if value, found := list[name]; !found {
+			list[name] = quantity.DeepCopy()
+		} else {
+			value.Add(quantity)
+			list[name] = value
+		}
+	}
 }
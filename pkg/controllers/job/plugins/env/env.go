@@ -29,6 +29,8 @@ import (
 )
 
 type envPlugin struct {
+	vkinterface.BasePlugin
+
 	// Arguments given for the plugin
 	pluginArguments []string
 
@@ -47,12 +49,7 @@ func (ep *envPlugin) Name() string {
 
 func (ep *envPlugin) OnPodCreate(pod *v1.Pod, job *vkv1.Job) error {
 	// use podName.serviceName as default pod DNS domain
-	if len(pod.Spec.Hostname) == 0 {
-		pod.Spec.Hostname = pod.Name
-	}
-	if len(pod.Spec.Subdomain) == 0 {
-		pod.Spec.Subdomain = job.Name
-	}
+	vkhelpers.SetDefaultDomain(pod, job)
 
 	// add VK_TASK_INDEX env to each container
 	for i, c := range pod.Spec.Containers {
@@ -73,9 +70,7 @@ func (ep *envPlugin) OnJobAdd(job *vkv1.Job) error {
 		return nil
 	}
 
-	data := generateHost(job)
-
-	if err := helpers.CreateConfigMapIfNotExist(job, ep.Clientset.KubeClients, data, ep.cmName(job)); err != nil {
+	if err := helpers.CreateConfigMapIfNotExist(job, ep.Clientset.KubeClients, generateHost(job), ep.cmName(job)); err != nil {
 		return err
 	}
 
@@ -92,6 +87,12 @@ func (ep *envPlugin) OnJobDelete(job *vkv1.Job) error {
 	return nil
 }
 
+func (ep *envPlugin) OnJobUpdate(job *vkv1.Job) error {
+	// The hosts of tasks are changed once the Job is scaled, regenerate them; the
+	// keys published by other plugins into the ConfigMap are kept, e.g. svc plugin.
+	return helpers.UpdateConfigMapIfChanged(job, ep.Clientset.KubeClients, generateHost(job), ep.cmName(job))
+}
+
 func (ep *envPlugin) mountConfigmap(pod *v1.Pod, job *vkv1.Job) {
 	cmName := ep.cmName(job)
 	cmVolume := v1.Volume{
@@ -121,15 +122,7 @@ func generateHost(job *vkv1.Job) map[string]string {
 		hosts := make([]string, 0, ts.Replicas)
 
 		for i := 0; i < int(ts.Replicas); i++ {
-			hostName := ts.Template.Spec.Hostname
-			subdomain := ts.Template.Spec.Subdomain
-			if len(hostName) == 0 {
-				hostName = fmt.Sprintf(vkhelpers.TaskNameFmt, job.Name, ts.Name, i)
-			}
-			if len(subdomain) == 0 {
-				subdomain = job.Name
-			}
-			hosts = append(hosts, hostName+"."+subdomain)
+			hosts = append(hosts, vkhelpers.MakeDomainName(ts, job, i))
 		}
 
 		key := fmt.Sprintf(ConfigMapTaskHostFmt, ts.Name)
@@ -21,12 +21,20 @@ import (
 
 	"volcano.sh/volcano/pkg/controllers/job/plugins/env"
 	"volcano.sh/volcano/pkg/controllers/job/plugins/interface"
+	"volcano.sh/volcano/pkg/controllers/job/plugins/mpi"
+	"volcano.sh/volcano/pkg/controllers/job/plugins/pytorch"
 	"volcano.sh/volcano/pkg/controllers/job/plugins/ssh"
+	"volcano.sh/volcano/pkg/controllers/job/plugins/svc"
+	"volcano.sh/volcano/pkg/controllers/job/plugins/tensorflow"
 )
 
 func init() {
 	RegisterPluginBuilder("ssh", ssh.New)
 	RegisterPluginBuilder("env", env.New)
+	RegisterPluginBuilder("tensorflow", tensorflow.New)
+	RegisterPluginBuilder("pytorch", pytorch.New)
+	RegisterPluginBuilder("mpi", mpi.New)
+	RegisterPluginBuilder("svc", svc.New)
 }
 
 var pluginMutex sync.Mutex
@@ -39,4 +39,40 @@ type PluginInterface interface {
 
 	// do once when killJob
 	OnJobDelete(job *vkv1.Job) error
+
+	// do once when syncJob after the spec of Job is updated, e.g. scaled
+	OnJobUpdate(job *vkv1.Job) error
+
+	// for the pod deleted or retained by controller, e.g. the failed pod
+	// when killJob or killTask
+	OnPodDelete(pod *v1.Pod, job *vkv1.Job) error
+
+	// do once when killJob restarts the Job, i.e. bumps its version
+	OnJobRestart(job *vkv1.Job, oldVersion, newVersion int32) error
+}
+
+// PluginValidator is implemented by the plugins which validate their arguments against
+// the spec of Job; it's called by the admission controller once the Job is created.
+type PluginValidator interface {
+	ValidateJobSpec(spec *vkv1.JobSpec) error
+}
+
+// BasePlugin implements the hooks of PluginInterface which do nothing, so plugins
+// embed it and only implement the hooks they care about.
+type BasePlugin struct{}
+
+func (bp *BasePlugin) OnJobDelete(job *vkv1.Job) error {
+	return nil
+}
+
+func (bp *BasePlugin) OnJobUpdate(job *vkv1.Job) error {
+	return nil
+}
+
+func (bp *BasePlugin) OnPodDelete(pod *v1.Pod, job *vkv1.Job) error {
+	return nil
+}
+
+func (bp *BasePlugin) OnJobRestart(job *vkv1.Job, oldVersion, newVersion int32) error {
+	return nil
 }
@@ -0,0 +1,223 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mpi
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+
+	"k8s.io/api/core/v1"
+
+	vkv1 "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
+	"volcano.sh/volcano/pkg/apis/helpers"
+	vkhelpers "volcano.sh/volcano/pkg/controllers/job/helpers"
+	vkinterface "volcano.sh/volcano/pkg/controllers/job/plugins/interface"
+)
+
+type mpiPlugin struct {
+	vkinterface.BasePlugin
+
+	// Arguments given for the plugin
+	pluginArguments []string
+
+	Clientset vkinterface.PluginClientset
+
+	// flag parse args
+	launcher string
+	worker   string
+	slots    int
+}
+
+func New(client vkinterface.PluginClientset, arguments []string) vkinterface.PluginInterface {
+	mpiPlugin := mpiPlugin{
+		pluginArguments: arguments,
+		Clientset:       client,
+		launcher:        DefaultLauncherTask,
+		worker:          DefaultWorkerTask,
+	}
+
+	mpiPlugin.addFlags()
+
+	return &mpiPlugin
+}
+
+func (mp *mpiPlugin) Name() string {
+	return "mpi"
+}
+
+func (mp *mpiPlugin) OnPodCreate(pod *v1.Pod, job *vkv1.Job) error {
+	var role string
+	switch pod.Annotations[vkv1.TaskSpecKey] {
+	case mp.launcher:
+		role = RoleLauncher
+	case mp.worker:
+		role = RoleWorker
+	default:
+		return nil
+	}
+
+	vkhelpers.SetDefaultDomain(pod, job)
+
+	// add role and MCA envs to each container
+	for i, c := range pod.Spec.Containers {
+		envs := append(c.Env, v1.EnvVar{Name: MPIRole, Value: role})
+		for _, name := range sortedKeys(defaultMCAEnvs) {
+			if !hasEnv(c, name) {
+				envs = append(envs, v1.EnvVar{Name: name, Value: defaultMCAEnvs[name]})
+			}
+		}
+		pod.Spec.Containers[i].Env = envs
+	}
+
+	mp.mountConfigmap(pod, job)
+
+	return nil
+}
+
+func (mp *mpiPlugin) OnJobAdd(job *vkv1.Job) error {
+	if job.Status.ControlledResources["plugin-"+mp.Name()] == mp.Name() {
+		return nil
+	}
+
+	if err := helpers.CreateConfigMapIfNotExist(job, mp.Clientset.KubeClients, mp.generateHostFile(job), mp.cmName(job)); err != nil {
+		return err
+	}
+
+	job.Status.ControlledResources["plugin-"+mp.Name()] = mp.Name()
+
+	return nil
+}
+
+func (mp *mpiPlugin) OnJobDelete(job *vkv1.Job) error {
+	if err := helpers.DeleteConfigmap(job, mp.Clientset.KubeClients, mp.cmName(job)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (mp *mpiPlugin) OnJobUpdate(job *vkv1.Job) error {
+	// The workers are changed once the Job is scaled, regenerate the hostfile.
+	return helpers.UpdateConfigMapIfChanged(job, mp.Clientset.KubeClients, mp.generateHostFile(job), mp.cmName(job))
+}
+
+func (mp *mpiPlugin) mountConfigmap(pod *v1.Pod, job *vkv1.Job) {
+	cmName := mp.cmName(job)
+	cmVolume := v1.Volume{
+		Name: cmName,
+	}
+	cmVolume.ConfigMap = &v1.ConfigMapVolumeSource{
+		LocalObjectReference: v1.LocalObjectReference{
+			Name: cmName,
+		},
+	}
+	pod.Spec.Volumes = append(pod.Spec.Volumes, cmVolume)
+
+	for i, c := range pod.Spec.Containers {
+		vm := v1.VolumeMount{
+			MountPath: ConfigMapMountPath,
+			Name:      cmName,
+		}
+
+		pod.Spec.Containers[i].VolumeMounts = append(c.VolumeMounts, vm)
+	}
+}
+
+// generateHostFile returns the hostfiles of OpenMPI and MPICH with the pods of worker task;
+// the slots of each host are given by argument, or the CPU request of worker.
+func (mp *mpiPlugin) generateHostFile(job *vkv1.Job) map[string]string {
+	var hostFile, machineFile []string
+
+	for _, ts := range job.Spec.Tasks {
+		if ts.Name != mp.worker {
+			continue
+		}
+
+		slots := mp.slots
+		if slots <= 0 {
+			slots = cpuSlots(&ts.Template.Spec)
+		}
+
+		for i := 0; i < int(ts.Replicas); i++ {
+			host := vkhelpers.MakeDomainName(ts, job, i)
+			hostFile = append(hostFile, fmt.Sprintf("%s slots=%d", host, slots))
+			machineFile = append(machineFile, fmt.Sprintf("%s:%d", host, slots))
+		}
+	}
+
+	return map[string]string{
+		HostFile:    strings.Join(hostFile, "\n"),
+		MachineFile: strings.Join(machineFile, "\n"),
+	}
+}
+
+// cpuSlots returns the number of whole CPUs requested by the containers of pod, at least 1.
+func cpuSlots(spec *v1.PodSpec) int {
+	var milliCPU int64
+	for _, c := range spec.Containers {
+		if cpu, found := c.Resources.Requests[v1.ResourceCPU]; found {
+			milliCPU += cpu.MilliValue()
+		} else if cpu, found := c.Resources.Limits[v1.ResourceCPU]; found {
+			milliCPU += cpu.MilliValue()
+		}
+	}
+
+	if slots := int(milliCPU / 1000); slots > 1 {
+		return slots
+	}
+	return 1
+}
+
+func hasEnv(c v1.Container, name string) bool {
+	for _, env := range c.Env {
+		if env.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (mp *mpiPlugin) cmName(job *vkv1.Job) string {
+	return fmt.Sprintf("%s-%s", job.Name, mp.Name())
+}
+
+func (mp *mpiPlugin) addFlags() {
+	flagSet := flag.NewFlagSet(mp.Name(), flag.ContinueOnError)
+	flagSet.StringVar(&mp.launcher, "launcher", mp.launcher, "The name of launcher task, which runs mpiexec")
+	flagSet.StringVar(&mp.worker, "worker", mp.worker, "The name of worker task, which is listed in hostfile")
+	flagSet.IntVar(&mp.slots, "slots", mp.slots, "The slots of each worker, the CPU request of worker by default")
+
+	if err := flagSet.Parse(mp.pluginArguments); err != nil {
+		glog.Errorf("plugin %s flagset parse failed, err: %v", mp.Name(), err)
+	}
+	return
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mpi
+
+const (
+	// HostFile is the hostfile of OpenMPI, e.g. `<host> slots=<n>`
+	HostFile = "hostfile"
+	// MachineFile is the hostfile of MPICH, e.g. `<host>:<n>`
+	MachineFile = "machinefile"
+
+	ConfigMapMountPath = "/etc/mpi"
+
+	// MPIRole is the env of the role of pod, e.g. launcher or worker
+	MPIRole = "VK_MPI_ROLE"
+
+	RoleLauncher = "launcher"
+	RoleWorker   = "worker"
+
+	DefaultLauncherTask = "mpimaster"
+	DefaultWorkerTask   = "mpiworker"
+)
+
+// defaultMCAEnvs are the defaults of OpenMPI MCA parameters for the pods
+// of Job, they're not set if given in container.
+var defaultMCAEnvs = map[string]string{
+	"OMPI_MCA_orte_default_hostfile":           ConfigMapMountPath + "/" + HostFile,
+	"OMPI_MCA_orte_keep_fqdn_hostnames":        "true",
+	"OMPI_MCA_plm_rsh_no_tree_spawn":           "true",
+	"OMPI_MCA_btl_vader_single_copy_mechanism": "none",
+}
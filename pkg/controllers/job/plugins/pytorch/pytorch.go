@@ -0,0 +1,208 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pytorch
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/golang/glog"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	vkv1 "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
+	"volcano.sh/volcano/pkg/apis/helpers"
+	vkhelpers "volcano.sh/volcano/pkg/controllers/job/helpers"
+	vkinterface "volcano.sh/volcano/pkg/controllers/job/plugins/interface"
+)
+
+type pytorchPlugin struct {
+	vkinterface.BasePlugin
+
+	// Arguments given for the plugin
+	pluginArguments []string
+
+	Clientset vkinterface.PluginClientset
+
+	// flag parse args
+	master string
+	port   int
+}
+
+func New(client vkinterface.PluginClientset, arguments []string) vkinterface.PluginInterface {
+	pytorchPlugin := pytorchPlugin{
+		pluginArguments: arguments,
+		Clientset:       client,
+		master:          DefaultMasterTask,
+		port:            DefaultPort,
+	}
+
+	pytorchPlugin.addFlags()
+
+	return &pytorchPlugin
+}
+
+func (pp *pytorchPlugin) Name() string {
+	return "pytorch"
+}
+
+func (pp *pytorchPlugin) OnPodCreate(pod *v1.Pod, job *vkv1.Job) error {
+	taskName := pod.Annotations[vkv1.TaskSpecKey]
+
+	index, err := strconv.Atoi(vkhelpers.GetTaskIndex(pod))
+	if err != nil {
+		return fmt.Errorf("failed to get index of pod %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+
+	masterAddr, rank, worldSize, err := pp.generateRank(job, taskName, index)
+	if err != nil {
+		return err
+	}
+
+	vkhelpers.SetDefaultDomain(pod, job)
+
+	envs := []v1.EnvVar{
+		{Name: EnvMasterAddr, Value: masterAddr},
+		{Name: EnvMasterPort, Value: strconv.Itoa(pp.port)},
+		{Name: EnvWorldSize, Value: strconv.Itoa(worldSize)},
+		{Name: EnvRank, Value: strconv.Itoa(rank)},
+	}
+
+	// add torch.distributed envs and port to the main container, not the sidecars
+	if len(pod.Spec.Containers) == 0 {
+		return nil
+	}
+	i := vkhelpers.MainContainerIndex(pod)
+	c := pod.Spec.Containers[i]
+	pod.Spec.Containers[i].Env = append(c.Env, envs...)
+
+	if !hasContainerPort(c, pp.port) {
+		port := v1.ContainerPort{
+			Name:          PortName,
+			ContainerPort: int32(pp.port),
+			Protocol:      v1.ProtocolTCP,
+		}
+		// the port is left unnamed if its name is used by another port of container
+		if hasContainerPortName(c, PortName) {
+			port.Name = ""
+		}
+		pod.Spec.Containers[i].Ports = append(c.Ports, port)
+	}
+
+	return nil
+}
+
+func (pp *pytorchPlugin) OnJobAdd(job *vkv1.Job) error {
+	if job.Status.ControlledResources["plugin-"+pp.Name()] == pp.Name() {
+		return nil
+	}
+
+	port := v1.ServicePort{
+		Name:       PortName,
+		Port:       int32(pp.port),
+		Protocol:   v1.ProtocolTCP,
+		TargetPort: intstr.FromInt(pp.port),
+	}
+	if err := helpers.AddServicePortsIfNotExist(job, pp.Clientset.KubeClients, []v1.ServicePort{port}); err != nil {
+		return err
+	}
+
+	job.Status.ControlledResources["plugin-"+pp.Name()] = pp.Name()
+
+	return nil
+}
+
+// ValidateJobSpec checks that the master task is one of the tasks of Job.
+func (pp *pytorchPlugin) ValidateJobSpec(spec *vkv1.JobSpec) error {
+	for _, ts := range spec.Tasks {
+		if ts.Name == pp.master {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("master task %s of plugin %s not found", pp.master, pp.Name())
+}
+
+// generateRank returns the address of master, the global rank of pod and the world size
+// of Job; the pods of master task come first, then the other tasks in the order of spec.
+func (pp *pytorchPlugin) generateRank(job *vkv1.Job, taskName string, index int) (string, int, int, error) {
+	var master *vkv1.TaskSpec
+	for i, ts := range job.Spec.Tasks {
+		if ts.Name == pp.master {
+			master = &job.Spec.Tasks[i]
+			break
+		}
+	}
+	if master == nil {
+		return "", 0, 0, fmt.Errorf("master task %s not found in job %s/%s",
+			pp.master, job.Namespace, job.Name)
+	}
+
+	rank := -1
+	worldSize := int(master.Replicas)
+	if taskName == master.Name {
+		rank = index
+	}
+	for _, ts := range job.Spec.Tasks {
+		if ts.Name == master.Name {
+			continue
+		}
+		if ts.Name == taskName {
+			rank = worldSize + index
+		}
+		worldSize += int(ts.Replicas)
+	}
+	if rank < 0 {
+		return "", 0, 0, fmt.Errorf("task %s not found in job %s/%s",
+			taskName, job.Namespace, job.Name)
+	}
+
+	return vkhelpers.MakeDomainName(*master, job, 0), rank, worldSize, nil
+}
+
+func hasContainerPort(c v1.Container, port int) bool {
+	for _, p := range c.Ports {
+		if p.ContainerPort == int32(port) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasContainerPortName(c v1.Container, name string) bool {
+	for _, p := range c.Ports {
+		if p.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (pp *pytorchPlugin) addFlags() {
+	flagSet := flag.NewFlagSet(pp.Name(), flag.ContinueOnError)
+	flagSet.StringVar(&pp.master, "master", pp.master, "The name of master task")
+	flagSet.IntVar(&pp.port, "port", pp.port, "The port of master to communicate")
+
+	if err := flagSet.Parse(pp.pluginArguments); err != nil {
+		glog.Errorf("plugin %s flagset parse failed, err: %v", pp.Name(), err)
+	}
+	return
+}
@@ -0,0 +1,32 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pytorch
+
+const (
+	// The environment variables of torch.distributed
+	EnvMasterAddr = "MASTER_ADDR"
+	EnvMasterPort = "MASTER_PORT"
+	EnvWorldSize  = "WORLD_SIZE"
+	EnvRank       = "RANK"
+
+	// DefaultMasterTask is the task of master if not specified
+	DefaultMasterTask = "master"
+	// DefaultPort is the port of master if not specified
+	DefaultPort = 23456
+	// PortName is the name of container port and Service port
+	PortName = "pytorchjob-port"
+)
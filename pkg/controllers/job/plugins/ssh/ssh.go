@@ -37,6 +37,8 @@ import (
 )
 
 type sshPlugin struct {
+	vkinterface.BasePlugin
+
 	// Arguments given for the plugin
 	pluginArguments []string
 
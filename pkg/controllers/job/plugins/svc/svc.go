@@ -0,0 +1,269 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package svc
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/golang/glog"
+
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	vkv1 "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
+	"volcano.sh/volcano/pkg/apis/helpers"
+	vkhelpers "volcano.sh/volcano/pkg/controllers/job/helpers"
+	vkinterface "volcano.sh/volcano/pkg/controllers/job/plugins/interface"
+)
+
+type servicePlugin struct {
+	vkinterface.BasePlugin
+
+	// Arguments given for the plugin
+	pluginArguments []string
+
+	Clientset vkinterface.PluginClientset
+
+	// flag parse args
+	perTask bool
+}
+
+func New(client vkinterface.PluginClientset, arguments []string) vkinterface.PluginInterface {
+	servicePlugin := servicePlugin{pluginArguments: arguments, Clientset: client}
+
+	servicePlugin.addFlags()
+
+	return &servicePlugin
+}
+
+func (sp *servicePlugin) Name() string {
+	return "svc"
+}
+
+func (sp *servicePlugin) OnPodCreate(pod *v1.Pod, job *vkv1.Job) error {
+	vkhelpers.SetDefaultDomain(pod, job)
+
+	return nil
+}
+
+func (sp *servicePlugin) OnJobAdd(job *vkv1.Job) error {
+	if job.Status.ControlledResources["plugin-"+sp.Name()] == sp.Name() {
+		return nil
+	}
+
+	var ports []v1.ServicePort
+	for _, ts := range job.Spec.Tasks {
+		ports = append(ports, servicePorts(ts)...)
+	}
+	if err := helpers.AddServicePortsIfNotExist(job, sp.Clientset.KubeClients, uniquePortNames(ports)); err != nil {
+		return err
+	}
+
+	if sp.perTask {
+		for _, ts := range job.Spec.Tasks {
+			if err := sp.createTaskServiceIfNotExist(job, ts); err != nil {
+				return err
+			}
+		}
+
+		// The Job is synced again if the ConfigMap of env plugin is not found.
+		if err := sp.publishDomains(job); err != nil {
+			return err
+		}
+	}
+
+	job.Status.ControlledResources["plugin-"+sp.Name()] = sp.Name()
+
+	return nil
+}
+
+func (sp *servicePlugin) OnJobDelete(job *vkv1.Job) error {
+	if !sp.perTask {
+		return nil
+	}
+
+	for _, ts := range job.Spec.Tasks {
+		name := taskServiceName(job, ts.Name)
+		svc, err := sp.Clientset.KubeClients.CoreV1().Services(job.Namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			glog.Errorf("Failed to get Service <%s/%s> of Job: %v",
+				job.Namespace, name, err)
+			return err
+		}
+		// Only delete the Service controlled by Job, see createTaskServiceIfNotExist.
+		if !metav1.IsControlledBy(svc, job) {
+			continue
+		}
+
+		options := &metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &svc.UID}}
+		if err := sp.Clientset.KubeClients.CoreV1().Services(job.Namespace).Delete(name, options); err != nil {
+			if !apierrors.IsNotFound(err) {
+				glog.Errorf("Failed to delete Service <%s/%s> of Job: %v",
+					job.Namespace, name, err)
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// createTaskServiceIfNotExist creates the headless Service of task, which selects the pods of task.
+func (sp *servicePlugin) createTaskServiceIfNotExist(job *vkv1.Job, ts vkv1.TaskSpec) error {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: job.Namespace,
+			Name:      taskServiceName(job, ts.Name),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(job, helpers.JobKind),
+			},
+		},
+		Spec: v1.ServiceSpec{
+			ClusterIP: "None",
+			Selector: map[string]string{
+				vkv1.JobNameKey:      job.Name,
+				vkv1.JobNamespaceKey: job.Namespace,
+				vkv1.TaskSpecKey:     ts.Name,
+			},
+			Ports: uniquePortNames(servicePorts(ts)),
+		},
+	}
+
+	if _, err := sp.Clientset.KubeClients.CoreV1().Services(job.Namespace).Create(svc); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			glog.V(3).Infof("Failed to create Service <%s/%s> for Job: %v",
+				svc.Namespace, svc.Name, err)
+			return err
+		}
+
+		// The name may be used by another Job, e.g. job "a" with task "b-c" and job "a-b" with task "c".
+		existing, err := sp.Clientset.KubeClients.CoreV1().Services(job.Namespace).Get(svc.Name, metav1.GetOptions{})
+		if err != nil {
+			glog.V(3).Infof("Failed to get Service <%s/%s> for Job: %v",
+				svc.Namespace, svc.Name, err)
+			return err
+		}
+		if !metav1.IsControlledBy(existing, job) {
+			return fmt.Errorf("service %s/%s of task %s already exists and is not controlled by job %s",
+				svc.Namespace, svc.Name, ts.Name, job.Name)
+		}
+	}
+
+	return nil
+}
+
+// publishDomains publishes the DNS names of task Services into the ConfigMap of env plugin, if it's enabled.
+func (sp *servicePlugin) publishDomains(job *vkv1.Job) error {
+	if _, found := job.Spec.Plugins[EnvPluginName]; !found {
+		return nil
+	}
+
+	cmName := envConfigMapName(job)
+	err := helpers.UpdateConfigMapIfChanged(job, sp.Clientset.KubeClients, sp.generateDomains(job), cmName)
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("ConfigMap %s of %s plugin is not found", cmName, EnvPluginName)
+	}
+
+	return err
+}
+
+// generateDomains returns the DNS names of task Services, which are reachable from other namespaces.
+func (sp *servicePlugin) generateDomains(job *vkv1.Job) map[string]string {
+	data := make(map[string]string, len(job.Spec.Tasks))
+
+	for _, ts := range job.Spec.Tasks {
+		key := fmt.Sprintf(ConfigMapTaskSvcFmt, ts.Name)
+		data[key] = fmt.Sprintf("%s.%s.svc", taskServiceName(job, ts.Name), job.Namespace)
+	}
+
+	return data
+}
+
+// servicePorts returns the ports of Service for the container ports declared in the template of task.
+func servicePorts(ts vkv1.TaskSpec) []v1.ServicePort {
+	var ports []v1.ServicePort
+
+	for _, c := range ts.Template.Spec.Containers {
+		for _, cp := range c.Ports {
+			protocol := cp.Protocol
+			if len(protocol) == 0 {
+				protocol = v1.ProtocolTCP
+			}
+			ports = append(ports, v1.ServicePort{
+				Name:       cp.Name,
+				Port:       cp.ContainerPort,
+				Protocol:   protocol,
+				TargetPort: intstr.FromInt(int(cp.ContainerPort)),
+			})
+		}
+	}
+
+	return ports
+}
+
+// uniquePortNames drops the duplicated ports, and names the ports whose name is empty
+// or used by others after their protocol and port, e.g. tcp-2222.
+func uniquePortNames(ports []v1.ServicePort) []v1.ServicePort {
+	result := make([]v1.ServicePort, 0, len(ports))
+	names := map[string]bool{}
+
+	for _, port := range ports {
+		duplicated := false
+		for _, p := range result {
+			if p.Port == port.Port && p.Protocol == port.Protocol {
+				duplicated = true
+				break
+			}
+		}
+		if duplicated {
+			continue
+		}
+
+		if len(port.Name) == 0 || names[port.Name] {
+			port.Name = helpers.DefaultServicePortName(port)
+		}
+		names[port.Name] = true
+		result = append(result, port)
+	}
+
+	return result
+}
+
+func taskServiceName(job *vkv1.Job, taskName string) string {
+	return fmt.Sprintf("%s-%s", job.Name, taskName)
+}
+
+// envConfigMapName returns the name of ConfigMap created by env plugin.
+func envConfigMapName(job *vkv1.Job) string {
+	return fmt.Sprintf("%s-%s", job.Name, EnvPluginName)
+}
+
+func (sp *servicePlugin) addFlags() {
+	flagSet := flag.NewFlagSet(sp.Name(), flag.ContinueOnError)
+	flagSet.BoolVar(&sp.perTask, "per-task", sp.perTask, "Create one Service for each task, and publish their DNS names by env plugin")
+
+	if err := flagSet.Parse(sp.pluginArguments); err != nil {
+		glog.Errorf("plugin %s flagset parse failed, err: %v", sp.Name(), err)
+	}
+	return
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tensorflow
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+
+	"k8s.io/api/core/v1"
+
+	vkv1 "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
+	vkhelpers "volcano.sh/volcano/pkg/controllers/job/helpers"
+	vkinterface "volcano.sh/volcano/pkg/controllers/job/plugins/interface"
+)
+
+type tfRole struct {
+	name string
+	task string
+	port int
+}
+
+type tensorflowPlugin struct {
+	vkinterface.BasePlugin
+
+	// Arguments given for the plugin
+	pluginArguments []string
+
+	Clientset vkinterface.PluginClientset
+
+	// flag parse args
+	port  int
+	roles []*tfRole
+}
+
+func New(client vkinterface.PluginClientset, arguments []string) vkinterface.PluginInterface {
+	tfPlugin := tensorflowPlugin{pluginArguments: arguments, Clientset: client, port: DefaultPort}
+
+	tfPlugin.addFlags()
+
+	return &tfPlugin
+}
+
+func (tp *tensorflowPlugin) Name() string {
+	return "tensorflow"
+}
+
+func (tp *tensorflowPlugin) OnPodCreate(pod *v1.Pod, job *vkv1.Job) error {
+	taskName := pod.Annotations[vkv1.TaskSpecKey]
+
+	role := tp.roleOfTask(taskName)
+	if role == nil {
+		return nil
+	}
+
+	index, err := strconv.Atoi(vkhelpers.GetTaskIndex(pod))
+	if err != nil {
+		return fmt.Errorf("failed to get index of pod %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+
+	vkhelpers.SetDefaultDomain(pod, job)
+
+	config := tfConfig{
+		Cluster: tp.generateCluster(job),
+		Task: tfTask{
+			Type:  role.name,
+			Index: index,
+		},
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+
+	// add TF_CONFIG env to each container
+	for i, c := range pod.Spec.Containers {
+		tfEnv := v1.EnvVar{
+			Name:  TFConfig,
+			Value: string(data),
+		}
+		pod.Spec.Containers[i].Env = append(c.Env, tfEnv)
+	}
+
+	return nil
+}
+
+func (tp *tensorflowPlugin) OnJobAdd(job *vkv1.Job) error {
+	return nil
+}
+
+func (tp *tensorflowPlugin) roleOfTask(taskName string) *tfRole {
+	for _, role := range tp.roles {
+		if role.task == taskName {
+			return role
+		}
+	}
+
+	return nil
+}
+
+// generateCluster returns the cluster spec of TensorFlow; the evaluator is not part
+// of the training cluster, so it's not included.
+func (tp *tensorflowPlugin) generateCluster(job *vkv1.Job) map[string][]string {
+	cluster := map[string][]string{}
+
+	for _, ts := range job.Spec.Tasks {
+		role := tp.roleOfTask(ts.Name)
+		if role == nil || role.name == RoleEvaluator {
+			continue
+		}
+
+		hosts := make([]string, 0, ts.Replicas)
+		for i := 0; i < int(ts.Replicas); i++ {
+			hosts = append(hosts, fmt.Sprintf("%s:%d", vkhelpers.MakeDomainName(ts, job, i), role.port))
+		}
+		cluster[role.name] = hosts
+	}
+
+	return cluster
+}
+
+func (tp *tensorflowPlugin) addFlags() {
+	var specs = map[string]*string{}
+
+	flagSet := flag.NewFlagSet(tp.Name(), flag.ContinueOnError)
+	flagSet.IntVar(&tp.port, "port", tp.port, "The default port of TensorFlow servers")
+	for _, name := range []string{RolePS, RoleWorker, RoleChief, RoleEvaluator} {
+		specs[name] = flagSet.String(name, name,
+			fmt.Sprintf("The task of %s role, in the format of <task>[:<port>]", name))
+	}
+
+	if err := flagSet.Parse(tp.pluginArguments); err != nil {
+		glog.Errorf("plugin %s flagset parse failed, err: %v", tp.Name(), err)
+	}
+
+	for _, name := range []string{RolePS, RoleWorker, RoleChief, RoleEvaluator} {
+		role := &tfRole{name: name, task: *specs[name], port: tp.port}
+		if parts := strings.SplitN(role.task, ":", 2); len(parts) == 2 {
+			port, err := strconv.Atoi(parts[1])
+			if err != nil {
+				glog.Errorf("plugin %s failed to parse port of %s role <%s>, err: %v",
+					tp.Name(), name, role.task, err)
+			} else {
+				role.task, role.port = parts[0], port
+			}
+		}
+		tp.roles = append(tp.roles, role)
+	}
+	return
+}
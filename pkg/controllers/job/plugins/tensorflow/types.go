@@ -0,0 +1,43 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tensorflow
+
+const (
+	// TFConfig is the environment variable of the cluster spec and task for TensorFlow
+	TFConfig = "TF_CONFIG"
+
+	// DefaultPort is the port of TensorFlow server if not specified
+	DefaultPort = 2222
+)
+
+// The roles of TensorFlow server
+const (
+	RolePS        = "ps"
+	RoleWorker    = "worker"
+	RoleChief     = "chief"
+	RoleEvaluator = "evaluator"
+)
+
+type tfTask struct {
+	Type  string `json:"type"`
+	Index int    `json:"index"`
+}
+
+type tfConfig struct {
+	Cluster map[string][]string `json:"cluster"`
+	Task    tfTask              `json:"task"`
+}
@@ -25,7 +25,7 @@ type abortedState struct {
 	job *apis.JobInfo
 }
 
-func (as *abortedState) Execute(action vkv1.Action) error {
+func (as *abortedState) Execute(action vkv1.Action, taskName string) error {
 	switch action {
 	case vkv1.ResumeJobAction:
 		return SyncJob(as.job, func(status vkv1.JobStatus) vkv1.JobState {
@@ -25,7 +25,7 @@ type abortingState struct {
 	job *apis.JobInfo
 }
 
-func (ps *abortingState) Execute(action vkv1.Action) error {
+func (ps *abortingState) Execute(action vkv1.Action, taskName string) error {
 	switch action {
 	case vkv1.ResumeJobAction:
 		// Already in Restarting phase, just sync it
@@ -25,7 +25,7 @@ type completingState struct {
 	job *apis.JobInfo
 }
 
-func (ps *completingState) Execute(action vkv1.Action) error {
+func (ps *completingState) Execute(action vkv1.Action, taskName string) error {
 	return KillJob(ps.job, func(status vkv1.JobStatus) vkv1.JobState {
 		// If any "alive" pods, still in Completing phase
 		if status.Terminating != 0 || status.Pending != 0 || status.Running != 0 {
@@ -23,17 +23,21 @@ import (
 
 type NextStateFn func(status vkv1.JobStatus) vkv1.JobState
 type ActionFn func(job *apis.JobInfo, fn NextStateFn) error
+type TaskActionFn func(job *apis.JobInfo, taskName string, fn NextStateFn) error
 
 var (
 	// SyncJob will create or delete Pods according to Job's spec.
 	SyncJob ActionFn
 	// KillJob kill all Pods of Job.
 	KillJob ActionFn
+	// KillTask kill all Pods of the task of Job, they'll be re-created by SyncJob.
+	KillTask TaskActionFn
 )
 
 type State interface {
-	// Execute executes the actions based on current state.
-	Execute(act vkv1.Action) error
+	// Execute executes the actions based on current state; taskName is the
+	// task which triggered the action, it's empty for Job level actions.
+	Execute(act vkv1.Action, taskName string) error
 }
 
 func NewState(jobInfo *apis.JobInfo) State {
@@ -45,7 +49,7 @@ func NewState(jobInfo *apis.JobInfo) State {
 		return &runningState{job: jobInfo}
 	case vkv1.Restarting:
 		return &restartingState{job: jobInfo}
-	case vkv1.Terminated, vkv1.Completed:
+	case vkv1.Terminated, vkv1.Completed, vkv1.Failed:
 		return &finishedState{job: jobInfo}
 	case vkv1.Terminating:
 		return &terminatingState{job: jobInfo}
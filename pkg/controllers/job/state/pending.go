@@ -25,10 +25,18 @@ type pendingState struct {
 	job *apis.JobInfo
 }
 
-func (ps *pendingState) Execute(action vkv1.Action) error {
+func (ps *pendingState) Execute(action vkv1.Action, taskName string) error {
+	if deadlineExceeded(ps.job.Job) {
+		return KillJob(ps.job, deadlineExceededState)
+	}
+
 	switch action {
 	case vkv1.RestartJobAction:
 		return KillJob(ps.job, func(status vkv1.JobStatus) vkv1.JobState {
+			if retryExhausted(ps.job.Job, status) {
+				return failedState(status)
+			}
+
 			phase := vkv1.Pending
 			if status.Terminating != 0 {
 				phase = vkv1.Restarting
@@ -39,6 +47,15 @@ func (ps *pendingState) Execute(action vkv1.Action) error {
 			}
 		})
 
+	case vkv1.RestartTaskAction:
+		if taskRetryExhausted(ps.job.Job, taskName) {
+			return KillJob(ps.job, taskFailedState(taskName))
+		}
+		return KillTask(ps.job, taskName, func(status vkv1.JobStatus) vkv1.JobState {
+			return vkv1.JobState{
+				Phase: vkv1.Pending,
+			}
+		})
 	case vkv1.AbortJobAction:
 		return KillJob(ps.job, func(status vkv1.JobStatus) vkv1.JobState {
 			phase := vkv1.Pending
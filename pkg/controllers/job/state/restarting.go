@@ -17,6 +17,8 @@ limitations under the License.
 package state
 
 import (
+	"time"
+
 	vkv1 "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
 	"volcano.sh/volcano/pkg/controllers/apis"
 )
@@ -25,7 +27,22 @@ type restartingState struct {
 	job *apis.JobInfo
 }
 
-func (ps *restartingState) Execute(action vkv1.Action) error {
+func (ps *restartingState) Execute(action vkv1.Action, taskName string) error {
+	// Keep killing the pods of the previous run by the order of tasks.
+	if TeardownInProgress(ps.job) {
+		return KillJob(ps.job, func(status vkv1.JobStatus) vkv1.JobState {
+			return vkv1.JobState{
+				Phase: vkv1.Restarting,
+			}
+		})
+	}
+
+	// Hold the pods back until the restart backoff expires; the Job will
+	// be synced again at that time.
+	if next := ps.job.Job.Status.NextRestartTime; next != nil && time.Now().Before(next.Time) {
+		return nil
+	}
+
 	return SyncJob(ps.job, func(status vkv1.JobStatus) vkv1.JobState {
 		phase := vkv1.Restarting
 		if status.Terminating == 0 {
@@ -25,10 +25,18 @@ type runningState struct {
 	job *apis.JobInfo
 }
 
-func (ps *runningState) Execute(action vkv1.Action) error {
+func (ps *runningState) Execute(action vkv1.Action, taskName string) error {
+	if deadlineExceeded(ps.job.Job) {
+		return KillJob(ps.job, deadlineExceededState)
+	}
+
 	switch action {
 	case vkv1.RestartJobAction:
 		return KillJob(ps.job, func(status vkv1.JobStatus) vkv1.JobState {
+			if retryExhausted(ps.job.Job, status) {
+				return failedState(status)
+			}
+
 			phase := vkv1.Running
 			if status.Terminating != 0 {
 				phase = vkv1.Restarting
@@ -38,6 +46,15 @@ func (ps *runningState) Execute(action vkv1.Action) error {
 				Phase: phase,
 			}
 		})
+	case vkv1.RestartTaskAction:
+		if taskRetryExhausted(ps.job.Job, taskName) {
+			return KillJob(ps.job, taskFailedState(taskName))
+		}
+		return KillTask(ps.job, taskName, func(status vkv1.JobStatus) vkv1.JobState {
+			return vkv1.JobState{
+				Phase: vkv1.Running,
+			}
+		})
 	case vkv1.AbortJobAction:
 		return KillJob(ps.job, func(status vkv1.JobStatus) vkv1.JobState {
 			phase := vkv1.Running
@@ -72,6 +89,11 @@ func (ps *runningState) Execute(action vkv1.Action) error {
 			}
 		})
 	default:
+		// Kill the remaining pods once the Job is succeeded.
+		if successPolicySatisfied(ps.job) {
+			return ps.Execute(vkv1.CompleteJobAction, taskName)
+		}
+
 		return SyncJob(ps.job, func(status vkv1.JobStatus) vkv1.JobState {
 			phase := vkv1.Running
 			if status.Succeeded+status.Failed == TotalTasks(ps.job.Job) {
@@ -25,17 +25,21 @@ type terminatingState struct {
 	job *apis.JobInfo
 }
 
-func (ps *terminatingState) Execute(action vkv1.Action) error {
+func (ps *terminatingState) Execute(action vkv1.Action, taskName string) error {
 	return KillJob(ps.job, func(status vkv1.JobStatus) vkv1.JobState {
 		// If any "alive" pods, still in Terminating phase
 		if status.Terminating != 0 || status.Pending != 0 || status.Running != 0 {
 			return vkv1.JobState{
-				Phase: vkv1.Terminating,
+				Phase:   vkv1.Terminating,
+				Reason:  status.State.Reason,
+				Message: status.State.Message,
 			}
 		}
 
 		return vkv1.JobState{
-			Phase: vkv1.Terminated,
+			Phase:   vkv1.Terminated,
+			Reason:  status.State.Reason,
+			Message: status.State.Message,
 		}
 	})
 }
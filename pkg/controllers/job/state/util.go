@@ -17,7 +17,15 @@ limitations under the License.
 package state
 
 import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"k8s.io/api/core/v1"
+
+	admissioncontroller "volcano.sh/volcano/pkg/admission"
 	vkv1 "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
+	"volcano.sh/volcano/pkg/controllers/apis"
 )
 
 func TotalTasks(job *vkv1.Job) int32 {
@@ -29,3 +37,128 @@ func TotalTasks(job *vkv1.Job) int32 {
 
 	return rep
 }
+
+// maxRetry returns 'maxRetry' of Job; the default is used if it's not set,
+// e.g. the Job was created without admission controller.
+func maxRetry(job *vkv1.Job) int32 {
+	if job.Spec.MaxRetry != nil {
+		return *job.Spec.MaxRetry
+	}
+
+	return admissioncontroller.DefaultMaxRetry
+}
+
+// retryExhausted returns true if the Job has been restarted 'maxRetry' times.
+func retryExhausted(job *vkv1.Job, status vkv1.JobStatus) bool {
+	return status.RetryCount >= maxRetry(job)
+}
+
+// taskRetryExhausted returns true if the task has been restarted 'maxRetry'
+// times; the restarts of a task are counted by its version, separately from
+// the restarts of Job.
+func taskRetryExhausted(job *vkv1.Job, taskName string) bool {
+	return job.Status.TaskVersions[taskName] >= maxRetry(job)
+}
+
+// failedState is the state of Job which can not be restarted anymore.
+func failedState(status vkv1.JobStatus) vkv1.JobState {
+	return vkv1.JobState{
+		Phase:   vkv1.Failed,
+		Reason:  "MaxRetryExceeded",
+		Message: fmt.Sprintf("Job has been restarted %d times", status.RetryCount),
+	}
+}
+
+// taskFailedState returns the state of Job whose task can not be restarted anymore.
+func taskFailedState(taskName string) NextStateFn {
+	return func(status vkv1.JobStatus) vkv1.JobState {
+		return vkv1.JobState{
+			Phase:   vkv1.Failed,
+			Reason:  "MaxRetryExceeded",
+			Message: fmt.Sprintf("Task %s has been restarted %d times", taskName, status.TaskVersions[taskName]),
+		}
+	}
+}
+
+// DeadlineRemaining returns the remaining time before Job exceeds its
+// 'activeDeadlineSeconds'; it's false if Job has no deadline or is not started.
+func DeadlineRemaining(job *vkv1.Job) (time.Duration, bool) {
+	if job.Spec.ActiveDeadlineSeconds == nil || job.Status.StartTime == nil {
+		return 0, false
+	}
+
+	deadline := time.Duration(*job.Spec.ActiveDeadlineSeconds) * time.Second
+	return job.Status.StartTime.Add(deadline).Sub(time.Now()), true
+}
+
+// deadlineExceeded returns true if Job was active longer than its 'activeDeadlineSeconds'.
+func deadlineExceeded(job *vkv1.Job) bool {
+	remaining, found := DeadlineRemaining(job)
+	return found && remaining <= 0
+}
+
+// deadlineExceededState is the state of Job which is terminated because of its deadline.
+func deadlineExceededState(status vkv1.JobStatus) vkv1.JobState {
+	phase := vkv1.Terminating
+	if status.Terminating == 0 && status.Pending == 0 && status.Running == 0 {
+		phase = vkv1.Terminated
+	}
+
+	return vkv1.JobState{
+		Phase:   phase,
+		Reason:  "DeadlineExceeded",
+		Message: "Job was active longer than specified deadline",
+	}
+}
+
+// successPolicySatisfied returns true if any of the success policies of Job is satisfied.
+func successPolicySatisfied(jobInfo *apis.JobInfo) bool {
+	for _, policy := range jobInfo.Job.Spec.SuccessPolicies {
+		minSucceeded := int32(0)
+		if policy.MinSucceeded != nil {
+			minSucceeded = *policy.MinSucceeded
+		} else {
+			for _, task := range jobInfo.Job.Spec.Tasks {
+				if task.Name == policy.TaskName {
+					minSucceeded = task.Replicas
+					break
+				}
+			}
+		}
+
+		var succeeded int32
+		for _, pod := range jobInfo.Pods[policy.TaskName] {
+			if pod.Status.Phase == v1.PodSucceeded {
+				succeeded++
+			}
+		}
+
+		if minSucceeded > 0 && succeeded >= minSucceeded {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TeardownInProgress returns whether the ordered teardown of Job is still in
+// progress, i.e. some pods of the killed version are kept to be killed later.
+func TeardownInProgress(jobInfo *apis.JobInfo) bool {
+	if policy := jobInfo.Job.Spec.TerminationPolicy; policy == nil || len(policy.TaskOrder) == 0 {
+		return false
+	}
+
+	for _, pods := range jobInfo.Pods {
+		for _, pod := range pods {
+			if pod.DeletionTimestamp != nil {
+				continue
+			}
+			version, err := strconv.Atoi(pod.Annotations[vkv1.JobVersion])
+			if err == nil && int32(version) < jobInfo.Job.Status.Version {
+				return true
+			}
+		}
+	}
+
+	return false
+}
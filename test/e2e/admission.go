@@ -152,4 +152,117 @@ var _ = Describe("Job E2E Test: Test Admission service", func() {
 		Expect(stError.ErrStatus.Code).To(Equal(int32(500)))
 		Expect(stError.ErrStatus.Message).To(ContainSubstring("unable to find job plugin: big_plugin"))
 	})
+
+	It("Circular task dependencies", func() {
+		jobName := "job-circular-dependencies"
+		namespace := "test"
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		_, err := createJobInner(context, &jobSpec{
+			min:       1,
+			namespace: namespace,
+			name:      jobName,
+			tasks: []taskSpec{
+				{
+					img:       defaultNginxImage,
+					req:       oneCPU,
+					min:       1,
+					rep:       1,
+					name:      "task-a",
+					dependsOn: []v1alpha1.TaskDependency{{Name: "task-b"}},
+				},
+				{
+					img:       defaultNginxImage,
+					req:       oneCPU,
+					rep:       1,
+					name:      "task-b",
+					dependsOn: []v1alpha1.TaskDependency{{Name: "task-a"}},
+				},
+				{
+					img:  defaultNginxImage,
+					req:  oneCPU,
+					min:  1,
+					rep:  1,
+					name: "task-c",
+				},
+			},
+		})
+		Expect(err).To(HaveOccurred())
+		stError, ok := err.(*errors.StatusError)
+		Expect(ok).To(Equal(true))
+		Expect(stError.ErrStatus.Code).To(Equal(int32(500)))
+		Expect(stError.ErrStatus.Message).To(ContainSubstring("circular dependency found"))
+	})
+
+	It("Main container not found", func() {
+		jobName := "job-main-container-illegal"
+		namespace := "test"
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		_, err := createJobInner(context, &jobSpec{
+			min:       1,
+			namespace: namespace,
+			name:      jobName,
+			tasks: []taskSpec{
+				{
+					img:  defaultNginxImage,
+					req:  oneCPU,
+					min:  1,
+					rep:  1,
+					name: "taskname",
+					annotations: map[string]string{
+						v1alpha1.MainContainerKey: "unknown",
+					},
+				},
+			},
+		})
+		Expect(err).To(HaveOccurred())
+		stError, ok := err.(*errors.StatusError)
+		Expect(ok).To(Equal(true))
+		Expect(stError.ErrStatus.Code).To(Equal(int32(500)))
+		Expect(stError.ErrStatus.Message).To(ContainSubstring("main container unknown is not found"))
+	})
+
+	It("Overlapped Policy ExitCodes", func() {
+		jobName := "job-policy-exitcodes-overlapped"
+		namespace := "test"
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		var exitCode int32 = 139
+		_, err := createJobInner(context, &jobSpec{
+			min:       1,
+			namespace: namespace,
+			name:      jobName,
+			tasks: []taskSpec{
+				{
+					img:  defaultNginxImage,
+					req:  oneCPU,
+					min:  1,
+					rep:  1,
+					name: "taskname",
+				},
+			},
+			policies: []v1alpha1.LifecyclePolicy{
+				{
+					ExitCodes: &v1alpha1.ExitCodeRequirement{
+						Operator: v1alpha1.ExitCodeOpIn,
+						Ranges:   []v1alpha1.ExitCodeRange{{Min: 137, Max: 143}},
+					},
+					Action: v1alpha1.RestartJobAction,
+				},
+				{
+					ExitCode: &exitCode,
+					Action:   v1alpha1.AbortJobAction,
+				},
+			},
+		})
+		Expect(err).To(HaveOccurred())
+		stError, ok := err.(*errors.StatusError)
+		Expect(ok).To(Equal(true))
+		Expect(stError.ErrStatus.Code).To(Equal(int32(500)))
+		Expect(stError.ErrStatus.Message).To(ContainSubstring("duplicate exitCode 139"))
+	})
 })
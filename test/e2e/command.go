@@ -65,6 +65,47 @@ var _ = Describe("Job E2E Test: Test Job Command", func() {
 			outBuffer.String())
 	})
 
+	It("View running job", func() {
+		var outBuffer bytes.Buffer
+		jobName := "test-view-job"
+		namespace := "test"
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		job := createJob(context, &jobSpec{
+			namespace: namespace,
+			name:      jobName,
+			tasks: []taskSpec{
+				{
+					name: "ps",
+					img:  defaultNginxImage,
+					min:  1,
+					rep:  1,
+				},
+				{
+					name: "worker",
+					img:  defaultNginxImage,
+					min:  2,
+					rep:  2,
+				},
+			},
+		})
+		//Job Status is running
+		err := waitJobStateReady(context, job)
+		Expect(err).NotTo(HaveOccurred())
+
+		job, err = context.vkclient.BatchV1alpha1().Jobs(namespace).Get(jobName, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(job.Status.TaskStatuses["ps"].Running).To(Equal(int32(1)))
+		Expect(job.Status.TaskStatuses["worker"].Running).To(Equal(int32(2)))
+
+		//Command outputs are identical
+		outputs := ViewJob(jobName, namespace)
+		ctlJob.PrintJob(job, &outBuffer)
+		Expect(outputs).To(Equal(outBuffer.String()), "View command result should be:\n %s",
+			outBuffer.String())
+	})
+
 	It("Suspend running job&Resume aborted job", func() {
 		jobName := "test-suspend-running-job"
 		taskName := "long-live-task"
@@ -17,12 +17,17 @@ limitations under the License.
 package e2e
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	vkv1 "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
 	jobutil "volcano.sh/volcano/pkg/controllers/job"
 )
@@ -507,4 +512,568 @@ var _ = Describe("Job Error Handling", func() {
 		Expect(err).NotTo(HaveOccurred())
 	})
 
+	It("job level LifecyclePolicy, Event: PodEvicted; Action: RestartJob; Timeout: 1m", func() {
+		By("init test context")
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		By("create job")
+		job := createJob(context, &jobSpec{
+			name: "evicted-delayed-restart-job",
+			policies: []vkv1.LifecyclePolicy{
+				{
+					Action:  vkv1.RestartJobAction,
+					Event:   vkv1.PodEvictedEvent,
+					Timeout: &metav1.Duration{Duration: time.Minute},
+				},
+			},
+			tasks: []taskSpec{
+				{
+					name: "delete",
+					img:  defaultNginxImage,
+					min:  2,
+					rep:  2,
+				},
+			},
+		})
+
+		// job phase: pending -> running
+		err := waitJobPhases(context, job, []vkv1.JobPhase{vkv1.Pending, vkv1.Running})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("delete one pod of job")
+		podName := jobutil.MakePodName(job.Name, "delete", 0)
+		err = context.kubeclient.CoreV1().Pods(job.Namespace).Delete(podName, &metav1.DeleteOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("the restart is delayed")
+		err = wait.Poll(100*time.Millisecond, oneMinute, func() (bool, error) {
+			newJob, err := context.vkclient.BatchV1alpha1().Jobs(job.Namespace).Get(job.Name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			return len(newJob.Status.DelayedActions) == 1, nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("the restart is cancelled after the pod is running again")
+		err = wait.Poll(100*time.Millisecond, oneMinute, func() (bool, error) {
+			newJob, err := context.vkclient.BatchV1alpha1().Jobs(job.Namespace).Get(job.Name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			return newJob.Status.Running == 2 && len(newJob.Status.DelayedActions) == 0, nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		newJob, err := context.vkclient.BatchV1alpha1().Jobs(job.Namespace).Get(job.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newJob.Status.Version).To(Equal(int32(0)))
+		Expect(newJob.Status.State.Phase).To(Equal(vkv1.Running))
+	})
+
+	It("job level LifecyclePolicy, Event: PodFailed; Action: RestartJob; MaxRetry: 1", func() {
+		By("init test context")
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		By("create job")
+		maxRetry := int32(1)
+		job := createJob(context, &jobSpec{
+			name:     "failed-max-retry-job",
+			maxRetry: &maxRetry,
+			policies: []vkv1.LifecyclePolicy{
+				{
+					Action: vkv1.RestartJobAction,
+					Event:  vkv1.PodFailedEvent,
+				},
+			},
+			tasks: []taskSpec{
+				{
+					name:          "fail",
+					img:           defaultNginxImage,
+					min:           1,
+					rep:           1,
+					command:       "sleep 10s && xxx",
+					restartPolicy: v1.RestartPolicyNever,
+				},
+			},
+		})
+
+		// job phase: pending -> running -> restarting -> running -> failed
+		err := waitJobPhases(context, job, []vkv1.JobPhase{
+			vkv1.Pending, vkv1.Running, vkv1.Restarting, vkv1.Running, vkv1.Failed})
+		Expect(err).NotTo(HaveOccurred())
+
+		newJob, err := context.vkclient.BatchV1alpha1().Jobs(job.Namespace).Get(job.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newJob.Status.RetryCount).To(Equal(int32(1)))
+	})
+
+	It("job level LifecyclePolicy, Event: PodFailed; Action: RestartJob; MaxRetry: 0", func() {
+		By("init test context")
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		By("create job")
+		maxRetry := int32(0)
+		job := createJob(context, &jobSpec{
+			name:     "failed-no-retry-job",
+			maxRetry: &maxRetry,
+			policies: []vkv1.LifecyclePolicy{
+				{
+					Action: vkv1.RestartJobAction,
+					Event:  vkv1.PodFailedEvent,
+				},
+			},
+			tasks: []taskSpec{
+				{
+					name:          "fail",
+					img:           defaultNginxImage,
+					min:           1,
+					rep:           1,
+					command:       "sleep 10s && xxx",
+					restartPolicy: v1.RestartPolicyNever,
+				},
+			},
+		})
+
+		// job phase: pending -> running -> failed
+		err := waitJobPhases(context, job, []vkv1.JobPhase{vkv1.Pending, vkv1.Running, vkv1.Failed})
+		Expect(err).NotTo(HaveOccurred())
+
+		newJob, err := context.vkclient.BatchV1alpha1().Jobs(job.Namespace).Get(job.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newJob.Status.RetryCount).To(Equal(int32(0)))
+	})
+
+	It("job level LifecyclePolicy, Event: PodEvicted; Action: RestartJob; RestartBackoff: 30s", func() {
+		By("init test context")
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		By("create job")
+		job := createJob(context, &jobSpec{
+			name: "evicted-backoff-restart-job",
+			policies: []vkv1.LifecyclePolicy{
+				{
+					Action: vkv1.RestartJobAction,
+					Event:  vkv1.PodEvictedEvent,
+				},
+			},
+			backoff: &vkv1.RestartBackoff{
+				InitialDelay: metav1.Duration{Duration: 30 * time.Second},
+			},
+			tasks: []taskSpec{
+				{
+					name: "delete",
+					img:  defaultNginxImage,
+					min:  2,
+					rep:  2,
+				},
+			},
+		})
+
+		// job phase: pending -> running
+		err := waitJobPhases(context, job, []vkv1.JobPhase{vkv1.Pending, vkv1.Running})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("delete one pod of job")
+		podName := jobutil.MakePodName(job.Name, "delete", 0)
+		err = context.kubeclient.CoreV1().Pods(job.Namespace).Delete(podName, &metav1.DeleteOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = waitJobPhases(context, job, []vkv1.JobPhase{vkv1.Restarting})
+		Expect(err).NotTo(HaveOccurred())
+		restartTime := time.Now()
+
+		newJob, err := context.vkclient.BatchV1alpha1().Jobs(job.Namespace).Get(job.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newJob.Status.NextRestartTime).NotTo(BeNil())
+
+		By("pods are re-created after the backoff")
+		err = waitJobPhases(context, job, []vkv1.JobPhase{vkv1.Running})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(time.Since(restartTime)).To(BeNumerically(">=", 20*time.Second))
+	})
+
+	It("Terminate job by the exit code of main container", func() {
+		By("init test context")
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		By("create job")
+		var erroCode int32 = 3
+		job := createJob(context, &jobSpec{
+			name: "main-container-job",
+			policies: []vkv1.LifecyclePolicy{
+				{
+					Action:   vkv1.TerminateJobAction,
+					ExitCode: &erroCode,
+				},
+			},
+			tasks: []taskSpec{
+				{
+					name:          "fail",
+					img:           defaultNginxImage,
+					min:           1,
+					rep:           1,
+					command:       "sleep 10s && exit 3",
+					restartPolicy: v1.RestartPolicyNever,
+					annotations: map[string]string{
+						vkv1.MainContainerKey: "nginx",
+					},
+					sidecars: []v1.Container{
+						{
+							Name:            "sidecar",
+							Image:           defaultBusyBoxImage,
+							ImagePullPolicy: v1.PullIfNotPresent,
+							Command:         []string{"/bin/sh", "-c", "exit 4"},
+						},
+					},
+				},
+			},
+		})
+
+		// job phase: pending -> running -> terminating -> terminated
+		err := waitJobPhases(context, job, []vkv1.JobPhase{vkv1.Pending, vkv1.Running, vkv1.Terminating, vkv1.Terminated})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("Terminate job by the range of exit codes", func() {
+		By("init test context")
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		By("create job")
+		job := createJob(context, &jobSpec{
+			name: "exitcodes-terminate-job",
+			policies: []vkv1.LifecyclePolicy{
+				{
+					Action: vkv1.RestartJobAction,
+					Events: []vkv1.Event{vkv1.PodEvictedEvent, vkv1.JobUnknownEvent},
+				},
+				{
+					Action: vkv1.TerminateJobAction,
+					ExitCodes: &vkv1.ExitCodeRequirement{
+						Operator: vkv1.ExitCodeOpIn,
+						Ranges:   []vkv1.ExitCodeRange{{Min: 2, Max: 5}},
+					},
+				},
+			},
+			tasks: []taskSpec{
+				{
+					name: "success",
+					img:  defaultNginxImage,
+					min:  1,
+					rep:  1,
+				},
+				{
+					name:          "fail",
+					img:           defaultNginxImage,
+					min:           1,
+					rep:           1,
+					command:       "sleep 10s && exit 3",
+					restartPolicy: v1.RestartPolicyNever,
+				},
+			},
+		})
+
+		// job phase: pending -> running -> terminating -> terminated
+		err := waitJobPhases(context, job, []vkv1.JobPhase{vkv1.Pending, vkv1.Running, vkv1.Terminating, vkv1.Terminated})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("Restart the failed task only", func() {
+		By("init test context")
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		By("create job")
+		job := createJob(context, &jobSpec{
+			name: "restart-task-job",
+			tasks: []taskSpec{
+				{
+					name: "success",
+					img:  defaultNginxImage,
+					min:  1,
+					rep:  1,
+				},
+				{
+					name:          "fail",
+					img:           defaultNginxImage,
+					min:           1,
+					rep:           1,
+					command:       "sleep 10s && exit 3",
+					restartPolicy: v1.RestartPolicyNever,
+					policies: []vkv1.LifecyclePolicy{
+						{
+							Action: vkv1.RestartTaskAction,
+							Event:  vkv1.PodFailedEvent,
+						},
+					},
+				},
+			},
+		})
+
+		err := waitJobReady(context, job)
+		Expect(err).NotTo(HaveOccurred())
+
+		pods := map[string]types.UID{}
+		for _, pod := range getTasksOfJob(context, job) {
+			pods[pod.Name] = pod.UID
+		}
+
+		By("wait for task restarted")
+		err = wait.Poll(100*time.Millisecond, oneMinute, func() (bool, error) {
+			newJob, err := context.vkclient.BatchV1alpha1().Jobs(job.Namespace).Get(job.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			return newJob.Status.TaskVersions["fail"] > 0, nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = waitJobReady(context, job)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, pod := range getTasksOfJob(context, job) {
+			if pod.Annotations[vkv1.TaskSpecKey] == "success" {
+				Expect(pod.UID).To(Equal(pods[pod.Name]))
+			} else {
+				Expect(pod.UID).NotTo(Equal(pods[pod.Name]))
+			}
+		}
+
+		newJob, err := context.vkclient.BatchV1alpha1().Jobs(job.Namespace).Get(job.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newJob.Status.Version).To(Equal(job.Status.Version))
+		Expect(newJob.Status.RetryCount).To(Equal(int32(0)))
+	})
+
+	It("job level LifecyclePolicy, Event: JobUnschedulable; Action: AbortJob", func() {
+		By("init test context")
+		context := initTestContext()
+		defer cleanupTestContext(context)
+		rep := clusterSize(context, oneCPU)
+
+		By("create job")
+		job := createJob(context, &jobSpec{
+			name: "unschedulable-abort-job",
+			policies: []vkv1.LifecyclePolicy{
+				{
+					Action: vkv1.AbortJobAction,
+					Event:  vkv1.JobUnschedulableEvent,
+				},
+			},
+			tasks: []taskSpec{
+				{
+					name: "unschedulable",
+					img:  defaultNginxImage,
+					req:  oneCPU,
+					min:  rep + 1,
+					rep:  rep + 1,
+				},
+			},
+		})
+
+		// job phase: pending -> aborting -> aborted
+		err := waitJobPhases(context, job, []vkv1.JobPhase{vkv1.Pending, vkv1.Aborting, vkv1.Aborted})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("job level LifecyclePolicy, Event: PodFailed; Action: AbortJob; MaxFailed: 1", func() {
+		By("init test context")
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		maxFailed := int32(1)
+
+		By("create job")
+		job := createJob(context, &jobSpec{
+			name: "failed-tolerated-job",
+			policies: []vkv1.LifecyclePolicy{
+				{
+					Action: vkv1.AbortJobAction,
+					Event:  vkv1.PodFailedEvent,
+				},
+			},
+			tasks: []taskSpec{
+				{
+					name:          "success",
+					img:           defaultNginxImage,
+					min:           2,
+					rep:           2,
+					command:       "sleep 20s",
+					restartPolicy: v1.RestartPolicyNever,
+				},
+				{
+					name:          "fail",
+					img:           defaultNginxImage,
+					min:           1,
+					rep:           1,
+					command:       "sleep 10s && xxx",
+					restartPolicy: v1.RestartPolicyNever,
+					maxFailed:     &maxFailed,
+				},
+			},
+		})
+
+		// job phase: pending -> running -> completed
+		err := waitJobPhases(context, job, []vkv1.JobPhase{vkv1.Pending, vkv1.Running, vkv1.Completed})
+		Expect(err).NotTo(HaveOccurred())
+
+		newJob, err := context.vkclient.BatchV1alpha1().Jobs(job.Namespace).Get(job.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newJob.Status.Tolerated).To(Equal(int32(1)))
+		Expect(newJob.Status.TaskStatuses["fail"].Tolerated).To(Equal(int32(1)))
+	})
+
+	It("job level LifecyclePolicy, Event: PodOOMKilled; Action: AbortJob", func() {
+		By("init test context")
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		By("create job")
+		job := createJob(context, &jobSpec{
+			name: "oom-abort-job",
+			policies: []vkv1.LifecyclePolicy{
+				{
+					Action: vkv1.AbortJobAction,
+					Event:  vkv1.PodOOMKilledEvent,
+				},
+				{
+					Action: vkv1.TerminateJobAction,
+					Event:  vkv1.PodFailedEvent,
+				},
+			},
+			tasks: []taskSpec{
+				{
+					name:          "oom",
+					img:           defaultBusyBoxImage,
+					min:           1,
+					rep:           1,
+					command:       "sleep 10s",
+					restartPolicy: v1.RestartPolicyNever,
+					sidecars: []v1.Container{
+						{
+							Name:            "oom",
+							Image:           defaultBusyBoxImage,
+							ImagePullPolicy: v1.PullIfNotPresent,
+							Command:         []string{"/bin/sh", "-c", "head -c 200m /dev/zero | tail"},
+							Resources: v1.ResourceRequirements{
+								Limits: v1.ResourceList{
+									v1.ResourceMemory: resource.MustParse("20Mi"),
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+
+		// job phase: pending -> running -> aborting -> aborted
+		err := waitJobPhases(context, job, []vkv1.JobPhase{vkv1.Pending, vkv1.Running, vkv1.Aborting, vkv1.Aborted})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("Retain the failed pods of restarted job", func() {
+		By("init test context")
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		By("create job")
+		maxRetry := int32(2)
+		job := createJob(context, &jobSpec{
+			name:     "failed-retain-job",
+			maxRetry: &maxRetry,
+			retention: &vkv1.RetentionPolicy{
+				MaxFailedPods: 1,
+			},
+			policies: []vkv1.LifecyclePolicy{
+				{
+					Action: vkv1.RestartJobAction,
+					Event:  vkv1.PodFailedEvent,
+				},
+			},
+			tasks: []taskSpec{
+				{
+					name:          "fail",
+					img:           defaultNginxImage,
+					min:           1,
+					rep:           1,
+					command:       "sleep 10s && exit 3",
+					restartPolicy: v1.RestartPolicyNever,
+				},
+			},
+		})
+
+		// job phase: pending -> running -> restarting -> running -> restarting -> running -> failed
+		err := waitJobPhases(context, job, []vkv1.JobPhase{
+			vkv1.Pending, vkv1.Running, vkv1.Restarting, vkv1.Running, vkv1.Restarting, vkv1.Running, vkv1.Failed})
+		Expect(err).NotTo(HaveOccurred())
+
+		By("only the last failed pod is retained")
+		err = wait.Poll(100*time.Millisecond, oneMinute, func() (bool, error) {
+			pods, err := context.kubeclient.CoreV1().Pods(job.Namespace).List(metav1.ListOptions{
+				LabelSelector: vkv1.RetainedPodKey + "=true",
+			})
+			if err != nil {
+				return false, err
+			}
+			var retained []v1.Pod
+			for _, pod := range pods.Items {
+				if pod.DeletionTimestamp == nil && pod.Annotations[vkv1.JobNameKey] == job.Name {
+					retained = append(retained, pod)
+				}
+			}
+			return len(retained) == 1 && retained[0].Status.Phase == v1.PodFailed &&
+				!metav1.IsControlledBy(&retained[0], job), nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		newJob, err := context.vkclient.BatchV1alpha1().Jobs(job.Namespace).Get(job.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newJob.Status.Failed).To(Equal(int32(0)))
+	})
+
+	It("Record the failure which restarted job", func() {
+		By("init test context")
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		By("create job")
+		job := createJob(context, &jobSpec{
+			name: "failed-diagnostics-job",
+			policies: []vkv1.LifecyclePolicy{
+				{
+					Action: vkv1.RestartJobAction,
+					Event:  vkv1.PodFailedEvent,
+				},
+			},
+			tasks: []taskSpec{
+				{
+					name:          "fail",
+					img:           defaultBusyBoxImage,
+					min:           1,
+					rep:           1,
+					command:       "sleep 10s && echo boom > /dev/termination-log && exit 3",
+					restartPolicy: v1.RestartPolicyNever,
+				},
+			},
+		})
+
+		// job phase: pending -> running -> restarting
+		err := waitJobPhases(context, job, []vkv1.JobPhase{vkv1.Pending, vkv1.Running, vkv1.Restarting})
+		Expect(err).NotTo(HaveOccurred())
+
+		newJob, err := context.vkclient.BatchV1alpha1().Jobs(job.Namespace).Get(job.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(newJob.Status.LastFailures)).NotTo(BeZero())
+
+		failure := newJob.Status.LastFailures[0]
+		Expect(failure.TaskName).To(Equal("fail"))
+		Expect(failure.Event).To(Equal(vkv1.PodFailedEvent))
+		Expect(failure.Action).To(Equal(vkv1.RestartJobAction))
+		Expect(failure.ExitCode).To(Equal(int32(3)))
+		Expect(failure.Message).To(Equal("boom"))
+	})
 })
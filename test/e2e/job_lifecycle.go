@@ -0,0 +1,374 @@
+/*
+Copyright 2019 The Volcano Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+
+	vkv1 "volcano.sh/volcano/pkg/apis/batch/v1alpha1"
+)
+
+var _ = Describe("Job E2E Test: Test Job Lifecycle", func() {
+	It("Delete completed job after TTL", func() {
+		By("init test context")
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		By("create job")
+		var ttl int32 = 5
+		job := createJob(context, &jobSpec{
+			name: "ttl-job",
+			ttl:  &ttl,
+			tasks: []taskSpec{
+				{
+					name:    "completed",
+					img:     defaultNginxImage,
+					min:     1,
+					rep:     1,
+					command: "sleep 5",
+				},
+			},
+		})
+
+		err := waitJobPhases(context, job, []vkv1.JobPhase{vkv1.Running, vkv1.Completed})
+		Expect(err).NotTo(HaveOccurred())
+
+		newJob, err := context.vkclient.BatchV1alpha1().Jobs(job.Namespace).Get(job.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newJob.Status.FinishTime).NotTo(BeNil())
+
+		By("job is deleted after TTL")
+		err = wait.Poll(100*time.Millisecond, oneMinute, func() (bool, error) {
+			_, err := context.vkclient.BatchV1alpha1().Jobs(job.Namespace).Get(job.Name, metav1.GetOptions{})
+			return apierrors.IsNotFound(err), nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("Terminate job after activeDeadlineSeconds", func() {
+		By("init test context")
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		By("create job")
+		var deadline int64 = 20
+		job := createJob(context, &jobSpec{
+			name:     "deadline-job",
+			deadline: &deadline,
+			tasks: []taskSpec{
+				{
+					name: "long-running",
+					img:  defaultNginxImage,
+					min:  2,
+					rep:  2,
+				},
+			},
+		})
+
+		// job phase: pending -> running -> terminating -> terminated
+		err := waitJobPhases(context, job, []vkv1.JobPhase{
+			vkv1.Pending, vkv1.Running, vkv1.Terminating, vkv1.Terminated})
+		Expect(err).NotTo(HaveOccurred())
+
+		newJob, err := context.vkclient.BatchV1alpha1().Jobs(job.Namespace).Get(job.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newJob.Status.State.Reason).To(Equal("DeadlineExceeded"))
+	})
+
+	It("Create the pods of task after its dependencies are running", func() {
+		By("init test context")
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		By("create job")
+		job := createJob(context, &jobSpec{
+			name: "depends-on-job",
+			min:  2,
+			tasks: []taskSpec{
+				{
+					name: "launcher",
+					img:  defaultNginxImage,
+					rep:  1,
+					dependsOn: []vkv1.TaskDependency{
+						{
+							Name:      "worker",
+							Condition: vkv1.DependencyRunning,
+						},
+					},
+				},
+				{
+					name: "worker",
+					img:  defaultNginxImage,
+					min:  2,
+					rep:  2,
+				},
+			},
+		})
+
+		err := waitJobReady(context, job)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = waitTasksReady(context, job, 3)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("launcher is created after workers are running")
+		var launcherCreated time.Time
+		var workerStarted []time.Time
+		for _, pod := range getTasksOfJob(context, job) {
+			if pod.Annotations[vkv1.TaskSpecKey] == "launcher" {
+				launcherCreated = pod.CreationTimestamp.Time
+				continue
+			}
+			Expect(pod.Status.StartTime).NotTo(BeNil())
+			workerStarted = append(workerStarted, pod.Status.StartTime.Time)
+		}
+		Expect(len(workerStarted)).To(Equal(2))
+		for _, started := range workerStarted {
+			Expect(launcherCreated.Before(started)).To(BeFalse())
+		}
+	})
+
+	It("Scale up and down the replicas of running job", func() {
+		By("init test context")
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		By("create job")
+		job := createJob(context, &jobSpec{
+			name: "elastic-job",
+			plugins: map[string][]string{
+				"env": {},
+			},
+			tasks: []taskSpec{
+				{
+					name: "worker",
+					img:  defaultNginxImage,
+					min:  2,
+					rep:  2,
+				},
+			},
+		})
+
+		err := waitJobReady(context, job)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("scale up job")
+		scaleJob(context, job, 3, 3)
+
+		err = waitTasksReady(context, job, 3)
+		Expect(err).NotTo(HaveOccurred())
+
+		pg, err := context.kbclient.SchedulingV1alpha1().PodGroups(job.Namespace).Get(job.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pg.Spec.MinMember).To(Equal(int32(3)))
+
+		cm, err := context.kubeclient.CoreV1().ConfigMaps(job.Namespace).Get(job.Name+"-env", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strings.Split(cm.Data["worker.host"], "\n")).To(HaveLen(3))
+
+		By("scale down job")
+		scaleJob(context, job, 1, 1)
+
+		err = wait.Poll(100*time.Millisecond, oneMinute, func() (bool, error) {
+			return len(getTasksOfJob(context, job)) == 1, nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("Reject updating the immutable fields of job", func() {
+		By("init test context")
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		By("create job")
+		job := createJob(context, &jobSpec{
+			name: "immutable-job",
+			tasks: []taskSpec{
+				{
+					name: "worker",
+					img:  defaultNginxImage,
+					min:  1,
+					rep:  1,
+				},
+			},
+		})
+
+		job, err := context.vkclient.BatchV1alpha1().Jobs(job.Namespace).Get(job.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		job.Spec.Tasks[0].Template.Spec.Containers[0].Image = defaultBusyBoxImage
+		_, err = context.vkclient.BatchV1alpha1().Jobs(job.Namespace).Update(job)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("job.spec is not allowed to modify"))
+	})
+
+	It("Record phase transitions in job conditions", func() {
+		By("init test context")
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		By("create job")
+		job := createJob(context, &jobSpec{
+			name: "conditions-job",
+			tasks: []taskSpec{
+				{
+					name:    "completed",
+					img:     defaultNginxImage,
+					min:     1,
+					rep:     1,
+					command: "sleep 5",
+				},
+			},
+		})
+
+		err := waitJobPhases(context, job, []vkv1.JobPhase{vkv1.Running, vkv1.Completed})
+		Expect(err).NotTo(HaveOccurred())
+
+		job, err = context.vkclient.BatchV1alpha1().Jobs(job.Namespace).Get(job.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		var phases []vkv1.JobPhase
+		for _, cond := range job.Status.Conditions {
+			Expect(cond.Status).To(Equal(v1.ConditionTrue))
+			phases = append(phases, cond.Type)
+		}
+		Expect(phases).To(ContainElement(vkv1.Running))
+		Expect(phases[len(phases)-1]).To(Equal(vkv1.Completed))
+	})
+
+	It("Complete job once the success policy is satisfied", func() {
+		By("init test context")
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		By("create job")
+		job := createJob(context, &jobSpec{
+			name: "success-policy-job",
+			success: []vkv1.SuccessPolicy{
+				{
+					TaskName: "master",
+				},
+			},
+			tasks: []taskSpec{
+				{
+					name:    "master",
+					img:     defaultNginxImage,
+					min:     1,
+					rep:     1,
+					command: "sleep 10",
+				},
+				{
+					name: "worker",
+					img:  defaultNginxImage,
+					min:  2,
+					rep:  2,
+				},
+			},
+		})
+
+		// job phase: running -> completing -> completed
+		err := waitJobPhases(context, job, []vkv1.JobPhase{vkv1.Running, vkv1.Completing, vkv1.Completed})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = wait.Poll(100*time.Millisecond, oneMinute, func() (bool, error) {
+			return len(getTasksOfJob(context, job)) == 0, nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("Kill tasks by the order of termination policy", func() {
+		By("init test context")
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		gracePeriod := int64(5)
+
+		By("create job")
+		job := createJob(context, &jobSpec{
+			name: "ordered-teardown-job",
+			teardown: &vkv1.TerminationPolicy{
+				GracePeriodSeconds: &gracePeriod,
+				TaskOrder:          []string{"chief"},
+			},
+			tasks: []taskSpec{
+				{
+					name: "chief",
+					img:  defaultNginxImage,
+					min:  1,
+					rep:  1,
+				},
+				{
+					name:    "worker",
+					img:     defaultBusyBoxImage,
+					min:     2,
+					rep:     2,
+					command: "sleep 1000",
+				},
+			},
+		})
+
+		err := waitJobReady(context, job)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("abort job")
+		SuspendJob(job.Name, job.Namespace)
+
+		// The chief is not killed until all workers are gone.
+		err = wait.Poll(100*time.Millisecond, oneMinute, func() (bool, error) {
+			var chiefTerminating bool
+			var workers int
+			for _, pod := range getTasksOfJob(context, job) {
+				if pod.Annotations[vkv1.TaskSpecKey] == "chief" {
+					chiefTerminating = pod.DeletionTimestamp != nil
+				} else {
+					workers++
+				}
+			}
+			Expect(chiefTerminating && workers != 0).To(BeFalse(),
+				"chief should not be killed before workers are gone")
+			return chiefTerminating, nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = waitJobStateAborted(context, job)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
+
+// scaleJob updates the replicas of the first task and the minAvailable of job.
+func scaleJob(context *context, job *vkv1.Job, replicas, minAvailable int32) {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current, err := context.vkclient.BatchV1alpha1().Jobs(job.Namespace).Get(job.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		current.Spec.Tasks[0].Replicas = replicas
+		current.Spec.MinAvailable = minAvailable
+		_, err = context.vkclient.BatchV1alpha1().Jobs(job.Namespace).Update(current)
+		return err
+	})
+	Expect(err).NotTo(HaveOccurred())
+}
@@ -17,10 +17,14 @@ limitations under the License.
 package e2e
 
 import (
+	"encoding/json"
 	"fmt"
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"volcano.sh/volcano/pkg/controllers/job/helpers"
 )
 
@@ -114,4 +118,182 @@ var _ = Describe("Job E2E Test: Test Job Plugins", func() {
 		}
 		Expect(foundVolume).To(BeTrue())
 	})
+
+	It("Tensorflow Plugin", func() {
+		jobName := "job-with-tf-plugin"
+		namespace := "test"
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		job := createJob(context, &jobSpec{
+			namespace: namespace,
+			name:      jobName,
+			plugins: map[string][]string{
+				"tensorflow": {"--worker=trainer:2223"},
+			},
+			tasks: []taskSpec{
+				{
+					img:  defaultNginxImage,
+					req:  oneCPU,
+					min:  1,
+					rep:  1,
+					name: "ps",
+				},
+				{
+					img:  defaultNginxImage,
+					req:  oneCPU,
+					min:  2,
+					rep:  2,
+					name: "trainer",
+				},
+			},
+		})
+
+		err := waitJobReady(context, job)
+		Expect(err).NotTo(HaveOccurred())
+
+		pod, err := context.kubeclient.CoreV1().Pods(namespace).Get(
+			fmt.Sprintf(helpers.TaskNameFmt, jobName, "trainer", 1), v1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		var tfConfig string
+		for _, env := range pod.Spec.Containers[0].Env {
+			if env.Name == "TF_CONFIG" {
+				tfConfig = env.Value
+				break
+			}
+		}
+		Expect(tfConfig).NotTo(BeEmpty())
+
+		config := struct {
+			Cluster map[string][]string `json:"cluster"`
+			Task    struct {
+				Type  string `json:"type"`
+				Index int    `json:"index"`
+			} `json:"task"`
+		}{}
+		err = json.Unmarshal([]byte(tfConfig), &config)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config.Task.Type).To(Equal("worker"))
+		Expect(config.Task.Index).To(Equal(1))
+		Expect(config.Cluster["ps"]).To(Equal([]string{
+			fmt.Sprintf("%s-ps-0.%s:2222", jobName, jobName)}))
+		Expect(config.Cluster["worker"]).To(Equal([]string{
+			fmt.Sprintf("%s-trainer-0.%s:2223", jobName, jobName),
+			fmt.Sprintf("%s-trainer-1.%s:2223", jobName, jobName)}))
+	})
+
+	It("Pytorch Plugin", func() {
+		jobName := "job-with-pytorch-plugin"
+		namespace := "test"
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		job := createJob(context, &jobSpec{
+			namespace: namespace,
+			name:      jobName,
+			plugins: map[string][]string{
+				"pytorch": {"--master=master", "--port=23456"},
+			},
+			tasks: []taskSpec{
+				{
+					img:  defaultNginxImage,
+					req:  oneCPU,
+					min:  2,
+					rep:  2,
+					name: "worker",
+				},
+				{
+					img:  defaultNginxImage,
+					req:  oneCPU,
+					min:  1,
+					rep:  1,
+					name: "master",
+				},
+			},
+		})
+
+		err := waitJobReady(context, job)
+		Expect(err).NotTo(HaveOccurred())
+
+		pod, err := context.kubeclient.CoreV1().Pods(namespace).Get(
+			fmt.Sprintf(helpers.TaskNameFmt, jobName, "worker", 1), v1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		envs := map[string]string{}
+		for _, env := range pod.Spec.Containers[0].Env {
+			envs[env.Name] = env.Value
+		}
+		Expect(envs["MASTER_ADDR"]).To(Equal(fmt.Sprintf("%s-master-0.%s", jobName, jobName)))
+		Expect(envs["MASTER_PORT"]).To(Equal("23456"))
+		Expect(envs["WORLD_SIZE"]).To(Equal("3"))
+		Expect(envs["RANK"]).To(Equal("2"))
+
+		svc, err := context.kubeclient.CoreV1().Services(namespace).Get(jobName, v1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(svc.Spec.Ports)).To(Equal(1))
+		Expect(svc.Spec.Ports[0].Port).To(Equal(int32(23456)))
+	})
+
+	It("Svc Plugin", func() {
+		jobName := "job-with-svc-plugin"
+		namespace := "test"
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		job := createJob(context, &jobSpec{
+			namespace: namespace,
+			name:      jobName,
+			plugins: map[string][]string{
+				"env": {},
+				"svc": {"--per-task"},
+			},
+			tasks: []taskSpec{
+				{
+					img:      defaultNginxImage,
+					req:      oneCPU,
+					min:      1,
+					rep:      1,
+					name:     "ps",
+					hostport: 28080,
+				},
+				{
+					img:      defaultNginxImage,
+					req:      oneCPU,
+					min:      1,
+					rep:      1,
+					name:     "worker",
+					hostport: 28081,
+				},
+			},
+		})
+
+		err := waitJobReady(context, job)
+		Expect(err).NotTo(HaveOccurred())
+
+		svc, err := context.kubeclient.CoreV1().Services(namespace).Get(jobName, v1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		var ports []int32
+		for _, port := range svc.Spec.Ports {
+			ports = append(ports, port.Port)
+		}
+		Expect(ports).To(ConsistOf(int32(28080), int32(28081)))
+
+		taskSvc, err := context.kubeclient.CoreV1().Services(namespace).Get(
+			fmt.Sprintf("%s-ps", jobName), v1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(taskSvc.Spec.Ports)).To(Equal(1))
+		Expect(taskSvc.Spec.Ports[0].Port).To(Equal(int32(28080)))
+
+		// The DNS names are published once the ConfigMap of env plugin is created.
+		err = wait.Poll(100*time.Millisecond, oneMinute, func() (bool, error) {
+			cm, err := context.kubeclient.CoreV1().ConfigMaps(namespace).Get(
+				fmt.Sprintf("%s-env", jobName), v1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			return cm.Data["ps.svc"] == fmt.Sprintf("%s-ps.%s.svc", jobName, namespace), nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
 })
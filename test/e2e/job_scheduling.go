@@ -21,6 +21,10 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 )
 
 var _ = Describe("Job E2E Test", func() {
@@ -285,4 +289,46 @@ var _ = Describe("Job E2E Test", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(evicted).NotTo(BeTrue())
 	})
+
+	It("Propagate priorityClassName and minResources to PodGroup", func() {
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		job := createJob(context, &jobSpec{
+			name: "pg-min-resources",
+			pri:  masterPriority,
+			min:  2,
+			tasks: []taskSpec{
+				{
+					name: "worker",
+					img:  defaultNginxImage,
+					req:  oneCPU,
+					pri:  workerPriority,
+					rep:  2,
+				},
+				{
+					name: "master",
+					img:  defaultNginxImage,
+					req:  cpuResource("500m"),
+					pri:  masterPriority,
+					rep:  1,
+				},
+			},
+		})
+
+		err := wait.Poll(100*time.Millisecond, oneMinute, func() (bool, error) {
+			_, err := context.kbclient.SchedulingV1alpha1().PodGroups(job.Namespace).Get(job.Name, metav1.GetOptions{})
+			return err == nil, nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		pg, err := context.kbclient.SchedulingV1alpha1().PodGroups(job.Namespace).Get(job.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pg.Spec.PriorityClassName).To(Equal(masterPriority))
+		Expect(pg.Spec.MinResources).NotTo(BeNil())
+
+		// The master with higher priority and one of workers.
+		minCPU := (*pg.Spec.MinResources)[v1.ResourceCPU]
+		Expect(minCPU.MilliValue()).To(Equal(int64(1500)))
+	})
 })
@@ -73,4 +73,53 @@ mpiexec --allow-run-as-root --hostfile /etc/volcano/mpiworker.host -np 2 mpi_hel
 		Expect(err).NotTo(HaveOccurred())
 	})
 
+	It("will run with mpi plugin and complete finally", func() {
+		context := initTestContext()
+		defer cleanupTestContext(context)
+
+		slot := oneCPU
+
+		spec := &jobSpec{
+			name: "mpi-plugin",
+			policies: []vkv1.LifecyclePolicy{
+				{
+					Action: vkv1.CompleteJobAction,
+					Event:  vkv1.TaskCompletedEvent,
+				},
+			},
+			plugins: map[string][]string{
+				"ssh": {},
+				"mpi": {"--launcher=mpimaster", "--worker=mpiworker"},
+			},
+			tasks: []taskSpec{
+				{
+					name:       "mpimaster",
+					img:        defaultMPIImage,
+					req:        slot,
+					min:        1,
+					rep:        1,
+					workingDir: "/home",
+					//Need sometime waiting for worker node ready
+					command: `sleep 5;
+mkdir -p /var/run/sshd; /usr/sbin/sshd;
+mpiexec --allow-run-as-root -np 2 mpi_hello_world > /home/re`,
+				},
+				{
+					name:       "mpiworker",
+					img:        defaultMPIImage,
+					req:        slot,
+					min:        2,
+					rep:        2,
+					workingDir: "/home",
+					command:    "mkdir -p /var/run/sshd; /usr/sbin/sshd -D;",
+				},
+			},
+		}
+
+		job := createJob(context, spec)
+
+		err := waitJobStates(context, job, []vkv1.JobPhase{
+			vkv1.Pending, vkv1.Running, vkv1.Completing, vkv1.Completed})
+		Expect(err).NotTo(HaveOccurred())
+	})
 })
@@ -288,6 +288,11 @@ type taskSpec struct {
 	policies              []vkv1.LifecyclePolicy
 	restartPolicy         v1.RestartPolicy
 	defaultGracefulPeriod *int64
+	dependsOn             []vkv1.TaskDependency
+	annotations           map[string]string
+	sidecars              []v1.Container
+	pri                   string
+	maxFailed             *int32
 }
 
 type jobSpec struct {
@@ -298,6 +303,15 @@ type jobSpec struct {
 	policies  []vkv1.LifecyclePolicy
 	min       int32
 	plugins   map[string][]string
+	maxRetry  *int32
+	backoff   *vkv1.RestartBackoff
+	ttl       *int32
+	deadline  *int64
+	pri       string
+	success   []vkv1.SuccessPolicy
+	maxFailed *int32
+	teardown  *vkv1.TerminationPolicy
+	retention *vkv1.RetentionPolicy
 }
 
 func getNS(context *context, job *jobSpec) string {
@@ -331,9 +345,18 @@ func createJobInner(context *context, jobSpec *jobSpec) (*vkv1.Job, error) {
 			Namespace: ns,
 		},
 		Spec: vkv1.JobSpec{
-			Policies: jobSpec.policies,
-			Queue:    jobSpec.queue,
-			Plugins:  jobSpec.plugins,
+			Policies:                jobSpec.policies,
+			Queue:                   jobSpec.queue,
+			Plugins:                 jobSpec.plugins,
+			MaxRetry:                jobSpec.maxRetry,
+			RestartBackoff:          jobSpec.backoff,
+			TTLSecondsAfterFinished: jobSpec.ttl,
+			ActiveDeadlineSeconds:   jobSpec.deadline,
+			PriorityClassName:       jobSpec.pri,
+			SuccessPolicies:         jobSpec.success,
+			MaxFailed:               jobSpec.maxFailed,
+			TerminationPolicy:       jobSpec.teardown,
+			RetentionPolicy:         jobSpec.retention,
 		},
 	}
 
@@ -349,20 +372,27 @@ func createJobInner(context *context, jobSpec *jobSpec) (*vkv1.Job, error) {
 			restartPolicy = task.restartPolicy
 		}
 
+		containers := append(append([]v1.Container{}, task.sidecars...),
+			createContainers(task.img, task.command, task.workingDir, task.req, task.hostport)...)
+
 		ts := vkv1.TaskSpec{
-			Name:     name,
-			Replicas: task.rep,
-			Policies: task.policies,
+			Name:      name,
+			Replicas:  task.rep,
+			Policies:  task.policies,
+			DependsOn: task.dependsOn,
+			MaxFailed: task.maxFailed,
 			Template: v1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:   name,
-					Labels: task.labels,
+					Name:        name,
+					Labels:      task.labels,
+					Annotations: task.annotations,
 				},
 				Spec: v1.PodSpec{
-					SchedulerName: "kube-batch",
-					RestartPolicy: restartPolicy,
-					Containers:    createContainers(task.img, task.command, task.workingDir, task.req, task.hostport),
-					Affinity:      task.affinity,
+					SchedulerName:     "kube-batch",
+					RestartPolicy:     restartPolicy,
+					Containers:        containers,
+					Affinity:          task.affinity,
+					PriorityClassName: task.pri,
 				},
 			},
 		}
@@ -495,7 +525,7 @@ func waitJobPhase(ctx *context, job *vkv1.Job, phase vkv1.JobPhase) error {
 			flag = newJob.Status.Pending > 0
 		case vkv1.Terminating, vkv1.Aborting, vkv1.Restarting:
 			flag = newJob.Status.Terminating > 0
-		case vkv1.Terminated, vkv1.Aborted:
+		case vkv1.Terminated, vkv1.Aborted, vkv1.Failed:
 			flag = newJob.Status.Pending == 0 &&
 				newJob.Status.Running == 0 &&
 				newJob.Status.Terminating == 0
@@ -801,11 +831,12 @@ func getTasksOfJob(ctx *context, job *vkv1.Job) []*v1.Pod {
 
 	var tasks []*v1.Pod
 
-	for _, pod := range pods.Items {
-		if !metav1.IsControlledBy(&pod, job) {
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !metav1.IsControlledBy(pod, job) {
 			continue
 		}
-		tasks = append(tasks, &pod)
+		tasks = append(tasks, pod)
 	}
 
 	return tasks